@@ -0,0 +1,527 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// fieldKind 决定为字段生成哪些方法
+type fieldKind int
+
+const (
+	kindValue   fieldKind = iota // 只生成 SetXxx
+	kindPointer                  // SetXxx 接收元素值
+	kindSlice                    // SetXxx 可变参数 + AddXxx
+	kindMap                      // SetXxx + SetXxxEntry
+	kindStruct                   // SetXxx + EditXxx
+)
+
+type field struct {
+	Name    string // 字段名
+	Method  string // 方法名后缀，首字母大写
+	Type    string
+	Kind    fieldKind
+	Key     string // map 的键类型
+	Elem    string // 指针、切片的元素类型，map 的值类型
+	Default string // 默认值的 Go 表达式，为空表示没有默认值
+}
+
+// generate 解析 path 所在的文件，为 typeName 生成 builder 源码
+func generate(path, typeName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	spec := findType(file, typeName)
+	if spec == nil {
+		return nil, fmt.Errorf("%s: type %s not found", path, typeName)
+	}
+	if spec.TypeParams != nil {
+		return nil, fmt.Errorf("%s: generic type %s is not supported", path, typeName)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s is not a struct", path, typeName)
+	}
+
+	pkg := packageTypes(packageFiles(fset, filepath.Dir(path), file))
+	var fields []field
+	for _, f := range st.Fields.List {
+		fs, err := newFields(f, pkg)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%w", typeName, err)
+		}
+		fields = append(fields, fs...)
+	}
+	if err := checkMethods(fields); err != nil {
+		return nil, fmt.Errorf("%s.%w", typeName, err)
+	}
+
+	src := render(file.Name.Name, typeName, fields, usedImports(file, st))
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, src)
+	}
+	return out, nil
+}
+
+// methods 为字段生成的方法名
+func (f field) methods() []string {
+	switch f.Kind {
+	case kindSlice:
+		return []string{"Set" + f.Method, "Add" + f.Method}
+	case kindMap:
+		return []string{"Set" + f.Method, "Set" + f.Method + "Entry"}
+	case kindStruct:
+		return []string{"Set" + f.Method, "Edit" + f.Method}
+	}
+	return []string{"Set" + f.Method}
+}
+
+// checkMethods 检查生成的方法名是否重复，如切片字段 Validator 的 AddValidator、
+// 字段 a 和 A 的 SetA、map 字段 M 的 SetMEntry 与字段 MEntry 的 SetMEntry
+func checkMethods(fields []field) error {
+	owner := map[string]string{"AddValidator": ""} // 名字与字段无关的方法中只有 AddValidator 可能冲突
+	for _, f := range fields {
+		for _, m := range f.methods() {
+			prev, ok := owner[m]
+			if !ok {
+				owner[m] = f.Name
+				continue
+			}
+			if prev == "" {
+				return fmt.Errorf("%s: method %s conflicts with the builder's own %s", f.Name, m, m)
+			}
+			return fmt.Errorf("%s: method %s conflicts with the one generated for field %s", f.Name, m, prev)
+		}
+	}
+	return nil
+}
+
+func findType(file *ast.File, name string) *ast.TypeSpec {
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range gd.Specs {
+			if ts := s.(*ast.TypeSpec); ts.Name.Name == name {
+				return ts
+			}
+		}
+	}
+	return nil
+}
+
+// packageFiles 同一目录下同一个包的所有非测试文件，file 排在第一个
+// 其他文件解析失败不影响生成，只是少识别一些类型和方法
+func packageFiles(fset *token.FileSet, dir string, file *ast.File) []*ast.File {
+	files := []*ast.File{file}
+	names, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") || filepath.Base(name) == filepath.Base(fset.Position(file.Pos()).Filename) {
+			continue
+		}
+		if f, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution); err == nil && f.Name.Name == file.Name.Name {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// pkgTypes 包中声明的非泛型类型
+type pkgTypes struct {
+	decls   map[string]ast.Expr // 类型名到类型定义
+	structs map[string]bool     // 结构体类型，用于识别嵌套结构体字段
+	basic   map[string]string   // 具名类型的底层基础类型，如 type Size int 中 Size 对应 int；底层不是基础类型时为空
+}
+
+func packageTypes(files []*ast.File) pkgTypes {
+	decls := map[string]ast.Expr{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, s := range gd.Specs {
+					if ts := s.(*ast.TypeSpec); ts.TypeParams == nil {
+						decls[ts.Name.Name] = ts.Type
+					}
+				}
+			}
+		}
+	}
+	pkg := pkgTypes{decls: decls, structs: map[string]bool{}, basic: map[string]string{}}
+	for name, e := range decls {
+		if _, ok := e.(*ast.StructType); ok {
+			pkg.structs[name] = true
+		}
+		// 沿 type A B 追到不是包内类型的标识符为止，步数限制防止非法的循环定义
+		for range len(decls) {
+			id, ok := e.(*ast.Ident)
+			if !ok {
+				break
+			}
+			next, ok := decls[id.Name]
+			if !ok {
+				pkg.basic[name] = id.Name
+				break
+			}
+			e = next
+		}
+		if _, ok := pkg.basic[name]; !ok {
+			pkg.basic[name] = ""
+		}
+	}
+	return pkg
+}
+
+func newFields(f *ast.Field, pkg pkgTypes) ([]field, error) {
+	proto := field{Type: types.ExprString(f.Type)}
+	switch t := f.Type.(type) {
+	case *ast.StarExpr:
+		proto.Kind, proto.Elem = kindPointer, types.ExprString(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			proto.Kind, proto.Elem = kindSlice, types.ExprString(t.Elt)
+		}
+	case *ast.MapType:
+		proto.Kind, proto.Key, proto.Elem = kindMap, types.ExprString(t.Key), types.ExprString(t.Value)
+	case *ast.StructType:
+		proto.Kind = kindStruct
+	case *ast.Ident:
+		if pkg.structs[t.Name] {
+			proto.Kind = kindStruct
+		}
+	}
+
+	var names []string
+	for _, n := range f.Names {
+		names = append(names, n.Name)
+	}
+	if len(names) == 0 {
+		names = []string{embeddedName(f.Type)}
+	}
+
+	var fields []field
+	for _, name := range names {
+		if name == "_" {
+			continue
+		}
+		fd := proto
+		fd.Name = name
+		fd.Method = exported(name)
+		if f.Tag != nil {
+			tag, _ := strconv.Unquote(f.Tag.Value)
+			if raw, ok := reflect.StructTag(tag).Lookup("default"); ok {
+				def, err := defaultExpr(fd, raw, pkg.basic)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				fd.Default = def
+			}
+		}
+		fields = append(fields, fd)
+	}
+	return fields, nil
+}
+
+// embeddedName 嵌入字段的字段名，即去掉指针和包名后的类型名
+func embeddedName(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	}
+	return types.ExprString(e)
+}
+
+func exported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// defaultExpr 把 default 标签转换成 Go 表达式，在生成时就检查取值是否合法
+func defaultExpr(f field, raw string, basic map[string]string) (string, error) {
+	switch f.Kind {
+	case kindValue:
+		return literal(basic, f.Type, raw)
+	case kindPointer:
+		lit, err := literal(basic, f.Elem, raw)
+		if err != nil {
+			return "", err
+		}
+		return f.Elem + "(" + lit + ")", nil
+	case kindSlice:
+		var items []string
+		if raw != "" {
+			for _, s := range strings.Split(raw, ",") {
+				lit, err := literal(basic, f.Elem, strings.TrimSpace(s))
+				if err != nil {
+					return "", err
+				}
+				items = append(items, lit)
+			}
+		}
+		return f.Type + "{" + strings.Join(items, ", ") + "}", nil
+	}
+	return "", fmt.Errorf("default tag is not supported for type %s", f.Type)
+}
+
+// literal 基础类型的字面量，包中底层为基础类型的具名类型按底层类型解析，
+// 生成的无类型常量可以直接赋给具名类型
+func literal(basic map[string]string, typ, raw string) (string, error) {
+	base := typ
+	if b, ok := basic[typ]; ok {
+		if b == "" {
+			return "", fmt.Errorf("default tag is not supported for type %s: underlying type is not a basic type", typ)
+		}
+		base = b
+	}
+	switch base {
+	case "byte":
+		base = "uint8"
+	case "rune":
+		base = "int32"
+	}
+	// 位数取自类型名，int、uint 按 64 位处理
+	bits := 64
+	if n, err := strconv.Atoi(strings.TrimLeft(base, "uintfloat")); err == nil {
+		bits = n
+	}
+	var err error
+	switch base {
+	case "string":
+		return strconv.Quote(raw), nil
+	case "bool":
+		var v bool
+		if v, err = strconv.ParseBool(raw); err == nil {
+			return strconv.FormatBool(v), nil
+		}
+	case "int", "int8", "int16", "int32", "int64":
+		var v int64
+		if v, err = strconv.ParseInt(raw, 0, bits); err == nil {
+			return strconv.FormatInt(v, 10), nil
+		}
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		var v uint64
+		if v, err = strconv.ParseUint(raw, 0, bits); err == nil {
+			return strconv.FormatUint(v, 10), nil
+		}
+	case "float32", "float64":
+		var v float64
+		if v, err = strconv.ParseFloat(raw, bits); err == nil {
+			return strconv.FormatFloat(v, 'g', -1, 64), nil
+		}
+	case "time.Duration":
+		var d time.Duration
+		if d, err = time.ParseDuration(raw); err == nil {
+			return durationExpr(d), nil
+		}
+	default:
+		return "", fmt.Errorf("default tag is not supported for type %s", typ)
+	}
+	return "", fmt.Errorf("invalid default %q for type %s: %w", raw, typ, err)
+}
+
+func durationExpr(d time.Duration) string {
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{{time.Hour, "Hour"}, {time.Minute, "Minute"}, {time.Second, "Second"}, {time.Millisecond, "Millisecond"}} {
+		if d != 0 && d%u.unit == 0 {
+			return fmt.Sprintf("%d * time.%s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", d)
+}
+
+var versionSuffix = regexp.MustCompile(`\.v\d+$`)
+
+// usedImports node 中用到的 file 的导入，返回 import 行（带别名时包含别名）
+func usedImports(file *ast.File, node ast.Node) []string {
+	used := map[string]bool{}
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	var lines []string
+	for _, imp := range file.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := versionSuffix.ReplaceAllString(path.Base(p), "")
+		line := strconv.Quote(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+			line = name + " " + line
+		}
+		if used[name] {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func render(pkg, typeName string, fields []field, imports []string) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	b := typeName + "Builder"
+	// copyFields 让 dst 中的切片、map 和指针字段不再与 src 共享存储（浅拷贝）
+	copyFields := func(dst, src string) {
+		for _, f := range fields {
+			switch f.Kind {
+			case kindSlice:
+				w("\t%s.%s = slices.Clone(%s.%s)", dst, f.Name, src, f.Name)
+			case kindMap:
+				w("\t%s.%s = maps.Clone(%s.%s)", dst, f.Name, src, f.Name)
+			case kindPointer:
+				w("\tif %s.%s != nil {", src, f.Name)
+				w("\t\t%s.%s = new(%s)", dst, f.Name, f.Elem)
+				w("\t\t*%s.%s = *%s.%s", dst, f.Name, src, f.Name)
+				w("\t}")
+			}
+		}
+	}
+
+	imports = append(imports, `"errors"`, `"slices"`)
+	for _, f := range fields {
+		if f.Kind == kindMap {
+			imports = append(imports, `"maps"`)
+		}
+	}
+	slices.Sort(imports)
+	imports = slices.Compact(imports)
+
+	w("// Code generated by buildergen -type %s; DO NOT EDIT.", typeName)
+	w("")
+	w("package %s", pkg)
+	w("")
+	w("import (")
+	for _, imp := range imports {
+		w("\t%s", imp)
+	}
+	w(")")
+	w("")
+	w("// %s %s 的 builder", b, typeName)
+	w("type %s struct {", b)
+	w("\tv          %s", typeName)
+	w("\tvalidators []func(%s) error", typeName)
+	w("}")
+	w("")
+	w("// New%s 创建 builder，并按 default 标签填充默认值", b)
+	w("func New%s() *%s {", b, b)
+	w("\tb := &%s{}", b)
+	for _, f := range fields {
+		switch {
+		case f.Default == "":
+		case f.Kind == kindPointer:
+			w("\tb.v.%s = new(%s)", f.Name, f.Elem)
+			w("\t*b.v.%s = %s", f.Name, f.Default)
+		default:
+			w("\tb.v.%s = %s", f.Name, f.Default)
+		}
+	}
+	w("\treturn b")
+	w("}")
+	w("")
+	w("// From%s 以已有的 %s 为起点创建 builder，不填充默认值", typeName, typeName)
+	w("func From%s(v %s) *%s {", typeName, typeName, b)
+	w("\tb := &%s{v: v}", b)
+	copyFields("b.v", "v")
+	w("\treturn b")
+	w("}")
+	w("")
+	w("// Clone 复制 builder，修改副本不影响原 builder")
+	w("func (b *%s) Clone() *%s {", b, b)
+	w("\tc := &%s{v: b.v, validators: slices.Clone(b.validators)}", b)
+	copyFields("c.v", "b.v")
+	w("\treturn c")
+	w("}")
+	for _, f := range fields {
+		w("")
+		switch f.Kind {
+		case kindPointer:
+			w("func (b *%s) Set%s(v %s) *%s {", b, f.Method, f.Elem, b)
+			w("\tb.v.%s = &v", f.Name)
+		case kindSlice:
+			w("func (b *%s) Set%s(v ...%s) *%s {", b, f.Method, f.Elem, b)
+			w("\tb.v.%s = slices.Clone(v)", f.Name)
+			w("\treturn b")
+			w("}")
+			w("")
+			w("func (b *%s) Add%s(v ...%s) *%s {", b, f.Method, f.Elem, b)
+			w("\tb.v.%s = append(b.v.%s, v...)", f.Name, f.Name)
+		case kindMap:
+			w("func (b *%s) Set%s(v %s) *%s {", b, f.Method, f.Type, b)
+			w("\tb.v.%s = maps.Clone(v)", f.Name)
+			w("\treturn b")
+			w("}")
+			w("")
+			w("func (b *%s) Set%sEntry(k %s, v %s) *%s {", b, f.Method, f.Key, f.Elem, b)
+			w("\tif b.v.%s == nil {", f.Name)
+			w("\t\tb.v.%s = %s{}", f.Name, f.Type)
+			w("\t}")
+			w("\tb.v.%s[k] = v", f.Name)
+		case kindStruct:
+			w("func (b *%s) Set%s(v %s) *%s {", b, f.Method, f.Type, b)
+			w("\tb.v.%s = v", f.Name)
+			w("\treturn b")
+			w("}")
+			w("")
+			w("// Edit%s 就地修改嵌套结构体", f.Method)
+			w("func (b *%s) Edit%s(fn func(*%s)) *%s {", b, f.Method, f.Type, b)
+			w("\tfn(&b.v.%s)", f.Name)
+		default:
+			w("func (b *%s) Set%s(v %s) *%s {", b, f.Method, f.Type, b)
+			w("\tb.v.%s = v", f.Name)
+		}
+		w("\treturn b")
+		w("}")
+	}
+	w("")
+	w("// AddValidator 追加校验钩子，Build 时在 %s.Validate 之后执行", typeName)
+	w("func (b *%s) AddValidator(fn func(%s) error) *%s {", b, typeName, b)
+	w("\tb.validators = append(b.validators, fn)")
+	w("\treturn b")
+	w("}")
+	w("")
+	w("// Build 执行 Validate 方法（如果有）和所有校验钩子，任一失败时返回合并后的错误")
+	w("func (b *%s) Build() (%s, error) {", b, typeName)
+	w("\tv := b.v")
+	copyFields("v", "b.v")
+	w("\tvar errs []error")
+	w("\tif h, ok := any(&v).(interface{ Validate() error }); ok {")
+	w("\t\terrs = append(errs, h.Validate())")
+	w("\t}")
+	w("\tfor _, fn := range b.validators {")
+	w("\t\terrs = append(errs, fn(v))")
+	w("\t}")
+	w("\tif err := errors.Join(errs...); err != nil {")
+	w("\t\treturn %s{}, err", typeName)
+	w("\t}")
+	w("\treturn v, nil")
+	w("}")
+	return buf.Bytes()
+}
@@ -0,0 +1,82 @@
+// buildergen 读取结构体定义，生成链式调用的 builder
+//
+//	//go:generate go run ../../cmd/buildergen -type Server
+//
+// 生成的 XxxBuilder 包含：
+//   - NewXxxBuilder：按字段的 default 标签填充默认值，如 `default:"16"`、`default:"a,b"`（切片），
+//     包中底层为基础类型的具名类型（如 type Size int）按底层类型解析
+//   - SetXxx：每个字段一个 setter；指针字段的 setter 接收元素值
+//   - AddXxx / SetXxxEntry：向切片追加元素、向 map 写入键值
+//   - EditXxx：就地修改嵌套结构体字段
+//   - AddValidator：追加校验钩子，与结构体自身的 Validate() error 方法一起在 Build 时执行
+//   - FromXxx / Clone：从已有的值或 builder 派生新的 builder
+//   - Build() (Xxx, error)：返回值中的切片、map 和指针字段与 builder 不共享存储（浅拷贝）
+//
+// 生成的方法重名时（如切片字段 Validator 的 AddValidator 与内置的 AddValidator）直接报错，不生成代码
+//
+// 指定 -steps 时改为在已有的 XxxBuilder（手写或生成的）之上生成分步 builder：
+//
+//	//go:generate go run ../../cmd/buildergen -type Computer -steps CPU,Memory
+//
+// 每个必填字段是一个阶段接口，只有按顺序设置完所有必填字段后才能拿到带 Build 方法的 XxxFinalStep，
+// 漏掉必填字段在编译期就会报错；各阶段方法在 XxxBuilder 的 Clone 上调用 SetXxx 并返回新的阶段值，
+// 默认值和校验与 XxxBuilder 相同，必填字段设置为零值时 Build 返回错误
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Getenv("GOFILE"), os.Stderr))
+}
+
+func run(args []string, gofile string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("buildergen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typeName := fs.String("type", "", "结构体名称（必填）")
+	steps := fs.String("steps", "", "生成分步 builder，按顺序列出必填字段，如 CPU,Memory")
+	output := fs.String("o", "", "输出文件，默认为输入文件所在目录下的 <type>_builder.go，分步 builder 为 <type>_steps.go")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: buildergen -type NAME [-steps FIELD,...] [-o FILE] [FILE]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 1 {
+		gofile = fs.Arg(0)
+	}
+	if *typeName == "" || gofile == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	out, suffix := *output, "_builder.go"
+	var (
+		src []byte
+		err error
+	)
+	if *steps == "" {
+		src, err = generate(gofile, *typeName)
+	} else {
+		suffix = "_steps.go"
+		src, err = generateSteps(gofile, *typeName, strings.Split(*steps, ","))
+	}
+	if out == "" {
+		out = filepath.Join(filepath.Dir(gofile), strings.ToLower(*typeName)+suffix)
+	}
+	if err == nil {
+		err = os.WriteFile(out, src, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "buildergen:", err)
+		return 1
+	}
+	return 0
+}
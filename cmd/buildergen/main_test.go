@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSource(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "types.go")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	return path
+}
+
+// 仓库中提交的生成代码必须与当前生成器的输出一致
+func TestGeneratedUpToDate(t *testing.T) {
+	const dir = "../../creational/builder/"
+	for _, tt := range []struct {
+		src, typ, out string
+		steps         []string
+	}{
+		{src: "server.go", typ: "Server", out: "server_builder.go"},
+		{src: "server.go", typ: "Server", out: "server_steps.go", steps: []string{"Name"}},
+		{src: "builder.go", typ: "Computer", out: "computer_steps.go", steps: []string{"CPU", "Memory"}},
+	} {
+		want, err := os.ReadFile(dir + tt.out)
+		require.NoError(t, err)
+		var got []byte
+		if tt.steps == nil {
+			got, err = generate(dir+tt.src, tt.typ)
+		} else {
+			got, err = generateSteps(dir+tt.src, tt.typ, tt.steps)
+		}
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), "%s is stale, run go generate ./creational/builder", tt.out)
+	}
+}
+
+func TestGenerateFieldKinds(t *testing.T) {
+	path := writeSource(t, `package demo
+
+import (
+	yml "gopkg.in/yaml.v3"
+	"net/url"
+)
+
+type Inner struct{ A int }
+
+type Outer struct {
+	Inner
+	*url.URL
+	Node    yml.Node
+	Meta    struct{ Tag string }
+	Ratio   *float32 `+"`default:\"0.5\"`"+`
+	Ports   []uint16 `+"`default:\"80, 443\"`"+`
+	Size    byte     `+"`default:\"0x10\"`"+`
+	a, b    bool     `+"`default:\"true\"`"+`
+	Matrix  [2]int
+	_       int
+}
+`)
+	src, err := generate(path, "Outer")
+	require.NoError(t, err)
+	out := string(src)
+	for _, want := range []string{
+		"\tyml \"gopkg.in/yaml.v3\"\n",
+		"\t\"net/url\"\n",
+		"func (b *OuterBuilder) SetInner(v Inner) *OuterBuilder {",
+		"func (b *OuterBuilder) EditInner(fn func(*Inner)) *OuterBuilder {",
+		"func (b *OuterBuilder) SetURL(v url.URL) *OuterBuilder {",
+		"func (b *OuterBuilder) SetNode(v yml.Node) *OuterBuilder {",
+		"func (b *OuterBuilder) EditMeta(fn func(*struct{ Tag string })) *OuterBuilder {",
+		"\t*b.v.Ratio = float32(0.5)\n",
+		"\tb.v.Ports = []uint16{80, 443}\n",
+		"func (b *OuterBuilder) AddPorts(v ...uint16) *OuterBuilder {",
+		"\tb.v.Size = 16\n",
+		"\tb.v.a = true\n\tb.v.b = true\n",
+		"func (b *OuterBuilder) SetA(v bool) *OuterBuilder {",
+		"func (b *OuterBuilder) SetMatrix(v [2]int) *OuterBuilder {",
+	} {
+		assert.Contains(t, out, want)
+	}
+	assert.NotContains(t, out, "Set_")
+	assert.NotContains(t, out, `"maps"`)
+}
+
+// 底层为基础类型的具名类型按底层类型解析 default 标签
+func TestGenerateNamedDefaults(t *testing.T) {
+	path := writeSource(t, `package demo
+
+type Size int
+type Level Size
+type Mode string
+
+type T struct {
+	Memory Size   `+"`default:\"32\"`"+`
+	Lvl    *Level `+"`default:\"0x2\"`"+`
+	Modes  []Mode `+"`default:\"a, b\"`"+`
+}
+`)
+	src, err := generate(path, "T")
+	require.NoError(t, err)
+	out := string(src)
+	for _, want := range []string{
+		"\tb.v.Memory = 32\n",
+		"\t*b.v.Lvl = Level(2)\n",
+		"\tb.v.Modes = []Mode{\"a\", \"b\"}\n",
+	} {
+		assert.Contains(t, out, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name, src, typ, err string
+	}{
+		{"missing", "package p\n", "T", "type T not found"},
+		{"not struct", "package p\ntype T int\n", "T", "T is not a struct"},
+		{"generic", "package p\ntype T[E any] struct{ V E }\n", "T", "generic type T is not supported"},
+		{"bad default", "package p\ntype T struct{ N int8 `default:\"300\"` }\n", "T",
+			`T.N: invalid default "300" for type int8`},
+		{"map default", "package p\ntype T struct{ M map[string]int `default:\"a\"` }\n", "T",
+			"T.M: default tag is not supported for type map[string]int"},
+		{"named default", "package p\ntype Tags []string\ntype T struct{ N Tags `default:\"a\"` }\n", "T",
+			"T.N: default tag is not supported for type Tags: underlying type is not a basic type"},
+		{"named bad default", "package p\ntype Size int\ntype T struct{ N Size `default:\"32GB\"` }\n", "T",
+			`T.N: invalid default "32GB" for type Size`},
+		{"validator", "package p\ntype T struct{ Validator []func(T) error }\n", "T",
+			"T.Validator: method AddValidator conflicts with the builder's own AddValidator"},
+		{"same method", "package p\ntype T struct{ a, A int }\n", "T",
+			"T.A: method SetA conflicts with the one generated for field a"},
+		{"entry", "package p\ntype T struct {\n\tM      map[string]int\n\tMEntry int\n}\n", "T",
+			"T.MEntry: method SetMEntry conflicts with the one generated for field M"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(writeSource(t, tt.src), tt.typ)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	path := writeSource(t, "package p\ntype Point struct{ X, Y int `default:\"1\"` }\n")
+	var stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"-type", "Point"}, path, &stderr), stderr.String())
+	src, err := os.ReadFile(filepath.Join(filepath.Dir(path), "point_builder.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "func NewPointBuilder() *PointBuilder {")
+
+	assert.Equal(t, 2, run(nil, path, &stderr))
+	assert.Equal(t, 1, run([]string{"-type", "Missing"}, path, &stderr))
+	assert.Contains(t, stderr.String(), "buildergen: ")
+}
+
+func TestGenerateStepsErrors(t *testing.T) {
+	const src = `package p
+
+import "time"
+
+type T struct {
+	A, B int
+	C    string
+	L    []int
+	D    time.Duration
+}
+
+type TBuilder struct{ v T }
+
+func NewTBuilder() *TBuilder               { return &TBuilder{} }
+func (b *TBuilder) Clone() *TBuilder       { c := *b; return &c }
+func (b *TBuilder) SetA(v int) *TBuilder   { b.v.A = v; return b }
+func (b *TBuilder) SetC(v string) *TBuilder { b.v.C = v; return b }
+func (b *TBuilder) SetL(v ...int) *TBuilder { b.v.L = v; return b }
+func (b *TBuilder) SetD(v time.Duration) *TBuilder { b.v.D = v; return b }
+func (b *TBuilder) Build() (T, error)      { return b.v, nil }
+
+type U struct{ A int }
+
+type V struct{ A int }
+
+type VBuilder struct{ v V }
+
+func NewVBuilder() *VBuilder { return &VBuilder{} }
+`
+	path := writeSource(t, src)
+	for steps, msg := range map[string]string{
+		"":    "T: no steps given",
+		"X":   "T: step X is not a field",
+		"B":   "T: TBuilder has no method SetB",
+		"A,A": "T: step A listed more than once",
+	} {
+		var list []string
+		if steps != "" {
+			list = strings.Split(steps, ",")
+		}
+		_, err := generateSteps(path, "T", list)
+		assert.EqualError(t, err, msg, steps)
+	}
+	_, err := generateSteps(path, "U", []string{"A"})
+	assert.EqualError(t, err, "U: func NewUBuilder() *UBuilder not found, run buildergen -type U first")
+	_, err = generateSteps(path, "V", []string{"A"})
+	assert.EqualError(t, err, "V: method (*VBuilder).Clone() *VBuilder not found")
+
+	out, err := generateSteps(path, "T", []string{"C"})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "\ts.zero[0] = v == \"\"\n\ts.b = s.b.Clone().SetC(v)\n")
+	assert.Contains(t, string(out), "type TCStep interface {\n\tSetC(v string) TFinalStep\n}")
+	assert.Contains(t, string(out), "type TFinalStep interface {\n\tSetA(v int) TFinalStep\n\tSetL(v ...int) TFinalStep\n\tSetD(v time.Duration) TFinalStep\n\tBuild() (T, error)\n}")
+
+	// 可变参数按长度判断零值，其他包的类型与零值变量比较
+	out, err = generateSteps(path, "T", []string{"L", "D"})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "\ts.zero[0] = len(v) == 0\n")
+	assert.Contains(t, string(out), "\tvar zero time.Duration\n\ts.zero[1] = v == zero\n")
+
+	var stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"-type", "T", "-steps", "C"}, path, &stderr), stderr.String())
+	_, err = os.Stat(filepath.Join(filepath.Dir(path), "t_steps.go"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// setter XxxBuilder 上的一个 SetXxx 方法
+type setter struct {
+	Name     string // 方法名
+	Param    string // 参数类型，可变参数不含 ...
+	Variadic bool
+	Expr     ast.Expr // 参数类型的语法树，可变参数为元素类型
+}
+
+func (s setter) signature(result string) string {
+	dots := ""
+	if s.Variadic {
+		dots = "..."
+	}
+	return fmt.Sprintf("%s(v %s%s) %s", s.Name, dots, s.Param, result)
+}
+
+func (s setter) call() string {
+	if s.Variadic {
+		return s.Name + "(v...)"
+	}
+	return s.Name + "(v)"
+}
+
+// isZero 判断参数 v 是否为零值的表达式，按包中类型的底层类型选择写法；
+// 其他类型与同类型的零值变量 zero 比较，此时 needVar 为 true
+func (s setter) isZero(decls map[string]ast.Expr) (cond string, needVar bool) {
+	if s.Variadic {
+		return "len(v) == 0", false
+	}
+	t := s.Expr
+	for range len(decls) {
+		id, ok := t.(*ast.Ident)
+		if !ok || decls[id.Name] == nil {
+			break
+		}
+		t = decls[id.Name]
+	}
+	switch t := t.(type) {
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "len(v) == 0", false
+		}
+	case *ast.MapType:
+		return "len(v) == 0", false
+	case *ast.StarExpr, *ast.FuncType, *ast.ChanType, *ast.InterfaceType:
+		return "v == nil", false
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return `v == ""`, false
+		case "bool":
+			return "!v", false
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "complex64", "complex128", "byte", "rune":
+			return "v == 0", false
+		}
+	}
+	return "v == zero", true
+}
+
+// generateSteps 在已有的 typeName+"Builder" 之上生成分步 builder：
+// steps 中的字段按顺序各占一个阶段，全部设置后才进入可以 Build 的最终阶段；
+// 必填字段设置为零值时 Build 返回错误，不会被 XxxBuilder 的默认值悄悄填上。
+// 每个阶段的方法都在 XxxBuilder 的 Clone 上修改并返回新的阶段值，保存下来的阶段互不影响
+func generateSteps(path, typeName string, steps []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	spec := findType(file, typeName)
+	if spec == nil {
+		return nil, fmt.Errorf("%s: type %s not found", path, typeName)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s is not a struct", path, typeName)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("%s: no steps given", typeName)
+	}
+
+	b := typeName + "Builder"
+	files := packageFiles(fset, filepath.Dir(path), file)
+	setters, imports, hasNew, hasClone := builderSetters(files, b)
+	if !hasNew {
+		return nil, fmt.Errorf("%s: func New%s() *%s not found, run buildergen -type %s first", typeName, b, b, typeName)
+	}
+	if !hasClone {
+		return nil, fmt.Errorf("%s: method (*%s).Clone() *%s not found", typeName, b, b)
+	}
+
+	var fields []string
+	for _, f := range st.Fields.List {
+		for _, n := range f.Names {
+			fields = append(fields, exported(n.Name))
+		}
+		if len(f.Names) == 0 {
+			fields = append(fields, exported(embeddedName(f.Type)))
+		}
+	}
+	var required, optional []setter
+	for _, step := range steps {
+		if !slices.Contains(fields, step) {
+			return nil, fmt.Errorf("%s: step %s is not a field", typeName, step)
+		}
+		if slices.ContainsFunc(required, func(s setter) bool { return s.Name == "Set"+step }) {
+			return nil, fmt.Errorf("%s: step %s listed more than once", typeName, step)
+		}
+		s, ok := setters["Set"+step]
+		if !ok {
+			return nil, fmt.Errorf("%s: %s has no method Set%s", typeName, b, step)
+		}
+		required = append(required, s)
+	}
+	for _, f := range fields {
+		if s, ok := setters["Set"+f]; ok && !slices.Contains(steps, f) {
+			optional = append(optional, s)
+		}
+	}
+
+	src := renderSteps(file.Name.Name, typeName, steps, required, optional, imports, packageTypes(files).decls)
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, src)
+	}
+	return out, nil
+}
+
+// builderSetters 收集 *builder 上只有一个参数、返回 *builder 的 SetXxx 方法，以及参数类型用到的导入，
+// 同时报告是否有 New 函数和 Clone 方法
+func builderSetters(files []*ast.File, builder string) (map[string]setter, []string, bool, bool) {
+	setters := map[string]setter{}
+	var imports []string
+	hasNew, hasClone := false, false
+	isBuilderPtr := func(e ast.Expr) bool {
+		star, ok := e.(*ast.StarExpr)
+		return ok && types.ExprString(star.X) == builder
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Type.Results == nil || len(fd.Type.Results.List) != 1 || !isBuilderPtr(fd.Type.Results.List[0].Type) {
+				continue
+			}
+			if fd.Recv == nil {
+				hasNew = hasNew || fd.Name.Name == "New"+builder && fd.Type.Params.NumFields() == 0
+				continue
+			}
+			params := fd.Type.Params.List
+			if isBuilderPtr(fd.Recv.List[0].Type) && fd.Name.Name == "Clone" && len(params) == 0 {
+				hasClone = true
+				continue
+			}
+			if !isBuilderPtr(fd.Recv.List[0].Type) || !strings.HasPrefix(fd.Name.Name, "Set") ||
+				len(params) != 1 || len(params[0].Names) > 1 {
+				continue
+			}
+			s := setter{Name: fd.Name.Name, Param: types.ExprString(params[0].Type), Expr: params[0].Type}
+			if e, ok := params[0].Type.(*ast.Ellipsis); ok {
+				s.Param, s.Variadic, s.Expr = types.ExprString(e.Elt), true, e.Elt
+			}
+			setters[s.Name] = s
+			imports = append(imports, usedImports(f, params[0].Type)...)
+		}
+	}
+	return setters, imports, hasNew, hasClone
+}
+
+func renderSteps(pkg, typeName string, steps []string, required, optional []setter, imports []string, decls map[string]ast.Expr) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+	impl := string(unicode.ToLower(rune(typeName[0]))) + typeName[1:] + "StepBuilder"
+	final := typeName + "FinalStep"
+	stage := func(i int) string {
+		if i == len(steps) {
+			return final
+		}
+		return typeName + steps[i] + "Step"
+	}
+	imports = append(imports, `"fmt"`)
+	slices.Sort(imports)
+	imports = slices.Compact(imports)
+
+	w("// Code generated by buildergen -type %s -steps %s; DO NOT EDIT.", typeName, strings.Join(steps, ","))
+	w("")
+	w("package %s", pkg)
+	if len(imports) > 0 {
+		w("")
+		w("import (")
+		for _, imp := range imports {
+			w("\t%s", imp)
+		}
+		w(")")
+	}
+	for i, s := range required {
+		w("")
+		w("// %s 第 %d 步，必须设置 %s", stage(i), i+1, steps[i])
+		w("type %s interface {", stage(i))
+		w("\t%s", s.signature(stage(i+1)))
+		w("}")
+	}
+	w("")
+	w("// %s 必填步骤已完成，可以设置可选字段并构建", final)
+	w("type %s interface {", final)
+	for _, s := range optional {
+		w("\t%s", s.signature(final))
+	}
+	w("\tBuild() (%s, error)", typeName)
+	w("}")
+	w("")
+	w("// New%sStepBuilder 按 %s 的顺序设置必填字段后才能调用 Build，默认值和校验与 %sBuilder 相同，",
+		typeName, strings.Join(steps, "、"), typeName)
+	w("// 必填字段设置为零值时 Build 返回错误")
+	w("func New%sStepBuilder() %s {", typeName, stage(0))
+	w("\treturn %s{b: New%sBuilder()}", impl, typeName)
+	w("}")
+	w("")
+	w("// %s 每次调用都返回修改过的副本，不改变调用它的阶段值", impl)
+	w("type %s struct {", impl)
+	w("\tb    *%sBuilder", typeName)
+	w("\tzero [%d]bool // 各必填字段是否被设置为零值", len(steps))
+	w("}")
+	for i, s := range required {
+		w("")
+		w("func (s %s) %s {", impl, s.signature(stage(i+1)))
+		cond, needVar := s.isZero(decls)
+		if needVar {
+			w("\tvar zero %s", s.Param)
+		}
+		w("\ts.zero[%d] = %s", i, cond)
+		w("\ts.b = s.b.Clone().%s", s.call())
+		w("\treturn s")
+		w("}")
+	}
+	for _, s := range optional {
+		w("")
+		w("func (s %s) %s {", impl, s.signature(final))
+		w("\ts.b = s.b.Clone().%s", s.call())
+		w("\treturn s")
+		w("}")
+	}
+	w("")
+	w("func (s %s) Build() (%s, error) {", impl, typeName)
+	w("\tfor i, step := range [...]string{%s} {", quoteAll(steps))
+	w("\t\tif s.zero[i] {")
+	w("\t\t\treturn %s{}, fmt.Errorf(\"%s: required step %%s must not be the zero value\", step)", typeName, strings.ToLower(typeName))
+	w("\t\t}")
+	w("\t}")
+	w("\treturn s.b.Build()")
+	w("}")
+	return buf.Bytes()
+}
+
+func quoteAll(ss []string) string {
+	q := make([]string, len(ss))
+	for i, s := range ss {
+		q[i] = strconv.Quote(s)
+	}
+	return strings.Join(q, ", ")
+}
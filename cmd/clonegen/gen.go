@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type generator struct {
+	types   map[string]*ast.TypeSpec // 包中声明的类型
+	targets []string                 // 要生成 Clone 的类型
+	buf     bytes.Buffer
+	tmp     int             // 临时变量编号
+	std     map[string]bool // 用到的标准库：slices、maps
+	pkgs    map[string]bool // 生成代码中引用到的其他包（按包名）
+}
+
+func (g *generator) w(format string, args ...any) { fmt.Fprintf(&g.buf, format+"\n", args...) }
+
+func (g *generator) newVar(prefix string) string {
+	g.tmp++
+	return prefix + strconv.Itoa(g.tmp)
+}
+
+// typeString 输出类型表达式并记录其中引用的包
+func (g *generator) typeString(e ast.Expr) string {
+	ast.Inspect(e, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				g.pkgs[id.Name] = true
+			}
+		}
+		return true
+	})
+	return types.ExprString(e)
+}
+
+// generate 解析 path 所在的包，为 typeNames 中的结构体生成 Clone 方法
+func generate(filename string, typeNames []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	files := []*ast.File{file}
+	others, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "*.go"))
+	for _, name := range others {
+		if strings.HasSuffix(name, "_test.go") || filepath.Base(name) == filepath.Base(filename) {
+			continue
+		}
+		// 其他文件解析失败时只是少识别一些类型
+		if f, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution); err == nil && f.Name.Name == file.Name.Name {
+			files = append(files, f)
+		}
+	}
+
+	g := &generator{types: map[string]*ast.TypeSpec{}, targets: typeNames, std: map[string]bool{}, pkgs: map[string]bool{}}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, s := range gd.Specs {
+					ts := s.(*ast.TypeSpec)
+					g.types[ts.Name.Name] = ts
+				}
+			}
+		}
+	}
+
+	var body bytes.Buffer
+	for _, name := range typeNames {
+		spec, ok := g.types[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: type %s not found", filename, name)
+		}
+		if spec.TypeParams != nil {
+			return nil, fmt.Errorf("%s: generic type %s is not supported", filename, name)
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return nil, fmt.Errorf("%s: %s is not a struct", filename, name)
+		}
+		g.buf.Reset()
+		g.tmp = 0
+		g.w("")
+		g.w("// Clone 返回 %s 的深拷贝", name)
+		g.w("func (x *%s) Clone() *%s {", name, name)
+		g.w("if x == nil {")
+		g.w("return nil")
+		g.w("}")
+		g.w("c := *x")
+		if err := g.copyFields("c", "x", st, map[string]bool{name: true}); err != nil {
+			return nil, fmt.Errorf("%s.%w", name, err)
+		}
+		g.w("return &c")
+		g.w("}")
+		body.Write(g.buf.Bytes())
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by clonegen -type %s; DO NOT EDIT.\n\npackage %s\n", strings.Join(typeNames, ","), file.Name.Name)
+	switch imports := g.importLines(files); len(imports) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&out, "\nimport %s\n", imports[0])
+	default:
+		fmt.Fprintf(&out, "\nimport (\n\t%s\n)\n", strings.Join(imports, "\n\t"))
+	}
+	out.Write(body.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// fieldError 嵌套字段上的错误，path 为从外层结构体开始的字段路径
+type fieldError struct {
+	path string
+	err  error
+}
+
+func (e *fieldError) Error() string { return e.path + ": " + e.err.Error() }
+func (e *fieldError) Unwrap() error { return e.err }
+
+// copyFields dst 已经是 src 的浅拷贝，为需要深拷贝或置零的字段生成赋值
+// visiting 为正在展开的包内类型，用于发现递归的类型
+func (g *generator) copyFields(dst, src string, st *ast.StructType, visiting map[string]bool) error {
+	for _, f := range st.Fields.List {
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		if len(names) == 0 {
+			names = append(names, embeddedName(f.Type))
+		}
+		tag := ""
+		if f.Tag != nil {
+			s, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(s).Get("clone")
+		}
+		for _, name := range names {
+			if name == "_" {
+				continue
+			}
+			switch tag {
+			case "shallow":
+			case "-":
+				g.w("%s.%s = %s", dst, name, g.zero(f.Type))
+			case "":
+				if g.plain(f.Type, nil) {
+					continue
+				}
+				if err := g.copyTo(dst+"."+name, src+"."+name, f.Type, visiting); err != nil {
+					if fe, ok := err.(*fieldError); ok {
+						return &fieldError{path: name + "." + fe.path, err: fe.err}
+					}
+					return &fieldError{path: name, err: err}
+				}
+			default:
+				return fmt.Errorf("%s: unknown clone tag %q", name, tag)
+			}
+		}
+	}
+	return nil
+}
+
+// zero 类型的零值表达式
+func (g *generator) zero(t ast.Expr) string {
+	switch g.underlying(t).(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return "nil"
+	case *ast.ArrayType:
+		if u := g.underlying(t).(*ast.ArrayType); u.Len == nil {
+			return "nil"
+		}
+	}
+	return "*new(" + g.typeString(t) + ")"
+}
+
+// underlying 包内命名类型的底层类型表达式
+func (g *generator) underlying(t ast.Expr) ast.Expr {
+	for range 16 {
+		switch e := t.(type) {
+		case *ast.ParenExpr:
+			t = e.X
+			continue
+		case *ast.Ident:
+			if spec, ok := g.types[e.Name]; ok && spec.TypeParams == nil {
+				t = spec.Type
+				continue
+			}
+		}
+		return t
+	}
+	return t
+}
+
+// plain 类型中没有需要深拷贝的部分，直接赋值即可
+// 接口、chan、func 和其他包的类型都视为 plain（浅拷贝）
+func (g *generator) plain(t ast.Expr, visiting map[string]bool) bool {
+	if id, ok := t.(*ast.Ident); ok {
+		if visiting[id.Name] {
+			return true
+		}
+		if _, declared := g.types[id.Name]; declared {
+			visiting = mapsWith(visiting, id.Name)
+		}
+	}
+	switch u := g.underlying(t).(type) {
+	case *ast.StarExpr, *ast.MapType:
+		return false
+	case *ast.ArrayType:
+		return u.Len != nil && g.plain(u.Elt, visiting)
+	case *ast.StructType:
+		for _, f := range u.Fields.List {
+			tag := ""
+			if f.Tag != nil {
+				s, _ := strconv.Unquote(f.Tag.Value)
+				tag = reflect.StructTag(s).Get("clone")
+			}
+			if tag == "-" || tag == "" && !g.plain(f.Type, visiting) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func mapsWith(m map[string]bool, k string) map[string]bool {
+	out := map[string]bool{k: true}
+	for key := range m {
+		out[key] = true
+	}
+	return out
+}
+
+// expr 能用单个表达式完成深拷贝时返回该表达式
+func (g *generator) expr(src string, t ast.Expr) (string, bool) {
+	if g.plain(t, nil) {
+		return src, true
+	}
+	switch u := g.underlying(t).(type) {
+	case *ast.StarExpr:
+		if id, ok := u.X.(*ast.Ident); ok && slices.Contains(g.targets, id.Name) {
+			return src + ".Clone()", true
+		}
+	case *ast.ArrayType:
+		if u.Len == nil && g.plain(u.Elt, nil) {
+			g.std["slices"] = true
+			return "slices.Clone(" + src + ")", true
+		}
+	case *ast.MapType:
+		if g.plain(u.Value, nil) {
+			g.std["maps"] = true
+			return "maps.Clone(" + src + ")", true
+		}
+	}
+	return "", false
+}
+
+// copyTo 生成把 dst 中的浅拷贝替换为深拷贝的语句，调用前 dst 已经等于 src
+// 再次遇到正在展开的类型时，生成的类型调用它的 Clone 方法，其他类型无法内联展开，返回错误
+func (g *generator) copyTo(dst, src string, t ast.Expr, visiting map[string]bool) error {
+	if e, ok := g.expr(src, t); ok {
+		if e != src {
+			g.w("%s = %s", dst, e)
+		}
+		return nil
+	}
+	if id, ok := t.(*ast.Ident); ok && g.types[id.Name] != nil {
+		if visiting[id.Name] {
+			if !slices.Contains(g.targets, id.Name) {
+				return fmt.Errorf("recursive type %s must be listed in -type", id.Name)
+			}
+			g.w("%s = *%s.Clone()", dst, src)
+			return nil
+		}
+		visiting = mapsWith(visiting, id.Name)
+	}
+	switch u := g.underlying(t).(type) {
+	case *ast.StarExpr:
+		p := g.newVar("p")
+		g.w("if %s != nil {", src)
+		g.w("%s := new(%s)", p, g.typeString(u.X))
+		g.w("*%s = *%s", p, src)
+		if err := g.copyTo("(*"+p+")", "(*"+src+")", u.X, visiting); err != nil {
+			return err
+		}
+		g.w("%s = %s", dst, p)
+		g.w("}")
+	case *ast.ArrayType:
+		if u.Len == nil {
+			// slices.Clone 保留 nil，随后逐个替换元素
+			g.std["slices"] = true
+			g.w("%s = slices.Clone(%s)", dst, src)
+		}
+		i := g.newVar("i")
+		g.w("for %s := range %s {", i, src)
+		if err := g.copyTo(dst+"["+i+"]", src+"["+i+"]", u.Elt, visiting); err != nil {
+			return err
+		}
+		g.w("}")
+	case *ast.MapType:
+		m, k, v := g.newVar("m"), g.newVar("k"), g.newVar("v")
+		g.w("if %s != nil {", src)
+		g.w("%s := make(%s, len(%s))", m, g.typeString(t), src)
+		g.w("for %s, %s := range %s {", k, v, src)
+		if e, ok := g.expr(v, u.Value); ok {
+			g.w("%s[%s] = %s", m, k, e)
+		} else {
+			// map 的元素不可寻址，先复制到临时变量
+			e := g.newVar("e")
+			g.w("%s := %s", e, v)
+			if err := g.copyTo(e, v, u.Value, visiting); err != nil {
+				return err
+			}
+			g.w("%s[%s] = %s", m, k, e)
+		}
+		g.w("}")
+		g.w("%s = %s", dst, m)
+		g.w("}")
+	case *ast.StructType:
+		return g.copyFields(dst, src, u, visiting)
+	}
+	return nil
+}
+
+var versionSuffix = regexp.MustCompile(`\.v\d+$`)
+
+// importLines 生成代码中用到的导入
+func (g *generator) importLines(files []*ast.File) []string {
+	var lines []string
+	for name := range g.std {
+		lines = append(lines, strconv.Quote(name))
+	}
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			name := versionSuffix.ReplaceAllString(path.Base(p), "")
+			line := strconv.Quote(p)
+			if imp.Name != nil {
+				name = imp.Name.Name
+				line = name + " " + line
+			}
+			if g.pkgs[name] {
+				lines = append(lines, line)
+				delete(g.pkgs, name)
+			}
+		}
+	}
+	slices.Sort(lines)
+	return slices.Compact(lines)
+}
+
+// embeddedName 嵌入字段的字段名
+func embeddedName(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	}
+	return types.ExprString(e)
+}
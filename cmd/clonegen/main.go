@@ -0,0 +1,61 @@
+// clonegen 为结构体生成深拷贝的 Clone 方法，避免手写时漏掉新增的字段
+//
+//	//go:generate go run ../../cmd/clonegen -type Character,Resume -o prototype_clone.go
+//
+// 生成的 func (x *T) Clone() *T：
+//   - 切片、map、指针、数组以及嵌套结构体逐层复制；元素不含引用时直接用 slices.Clone / maps.Clone
+//   - 指向同一批生成类型的指针调用其 Clone 方法；递归的类型（如 Children []Node）必须列在 -type 中，否则报错
+//   - 接口、chan、func 以及其他包的类型按值复制（浅拷贝）
+//   - 字段标签 `clone:"shallow"` 表示与原值共享，`clone:"-"` 表示副本中为零值
+//
+// 修改结构体后重新运行 go generate 即可
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Getenv("GOFILE"), os.Stderr))
+}
+
+func run(args []string, gofile string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("clonegen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typeNames := fs.String("type", "", "逗号分隔的结构体名称（必填）")
+	output := fs.String("o", "", "输出文件，默认为输入文件所在目录下的 <第一个类型>_clone.go")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: clonegen -type NAME[,NAME...] [-o FILE] [FILE]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 1 {
+		gofile = fs.Arg(0)
+	}
+	if *typeNames == "" || gofile == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	names := strings.Split(*typeNames, ",")
+	out := *output
+	if out == "" {
+		out = filepath.Join(filepath.Dir(gofile), strings.ToLower(names[0])+"_clone.go")
+	}
+	src, err := generate(gofile, names)
+	if err == nil {
+		err = os.WriteFile(out, src, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "clonegen:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSource(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "types.go")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	return path
+}
+
+// 仓库中提交的生成代码必须与当前生成器的输出一致
+func TestGeneratedUpToDate(t *testing.T) {
+	const dir = "../../creational/prototype/"
+	want, err := os.ReadFile(dir + "prototype_clone.go")
+	require.NoError(t, err)
+	got, err := generate(dir+"prototype.go", []string{"Character", "Resume", "Team"})
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "prototype_clone.go is stale, run go generate ./creational/prototype")
+}
+
+func TestGenerateFieldKinds(t *testing.T) {
+	path := writeSource(t, `package demo
+
+import (
+	yml "gopkg.in/yaml.v3"
+	"net/url"
+)
+
+type Point struct{ X, Y int }
+
+type Inner struct {
+	Tags []string
+}
+
+type IDs []int
+
+type Node struct {
+	Next *Node
+	Val  int
+}
+
+type Outer struct {
+	Inner
+	*url.URL
+	Doc      yml.Node
+	Point    Point
+	PointPtr *Point
+	Nested   struct{ M map[string]*Point }
+	Grid     [2][]int
+	Fixed    [3]Point
+	Lookup   map[string]Inner
+	IDs      IDs
+	Head     *Node
+	Any      any
+	Ch       chan int
+	Fn       func()
+	Shared   []int          `+"`clone:\"shallow\"`"+`
+	Cache    map[string]int `+"`clone:\"-\"`"+`
+	Loaded   Point          `+"`clone:\"-\"`"+`
+	_        int
+}
+`)
+	src, err := generate(path, []string{"Outer", "Node"})
+	require.NoError(t, err)
+	out := string(src)
+	for _, want := range []string{
+		"// Code generated by clonegen -type Outer,Node; DO NOT EDIT.\n",
+		"\t\"net/url\"\n\t\"slices\"\n",
+		"\tc.Inner.Tags = slices.Clone(x.Inner.Tags)\n",
+		"\tif x.URL != nil {\n\t\tp1 := new(url.URL)\n\t\t*p1 = *x.URL\n\t\tc.URL = p1\n\t}\n",
+		"\tif x.PointPtr != nil {\n",
+		"\tif x.Nested.M != nil {\n",
+		"\tfor i8 := range x.Grid {\n\t\tc.Grid[i8] = slices.Clone(x.Grid[i8])\n\t}\n",
+		"\tc.IDs = slices.Clone(x.IDs)\n",
+		"\tc.Head = x.Head.Clone()\n",
+		"\tc.Cache = nil\n",
+		"\tc.Loaded = *new(Point)\n",
+		"func (x *Node) Clone() *Node {",
+	} {
+		assert.Contains(t, out, want)
+	}
+	// 值类型、接口、chan、func 以及 shallow 字段随 c := *x 一起复制
+	for _, absent := range []string{"c.Inner =", "c.Doc", "c.Point ", "c.Fixed", "c.Any", "c.Ch", "c.Fn", "c.Shared", "yaml", "maps"} {
+		assert.NotContains(t, out, absent)
+	}
+	// map 的值包含切片，需要逐个复制
+	assert.Contains(t, out, "\tif x.Lookup != nil {\n")
+	assert.Contains(t, out, "\t\t\te12 := v11\n\t\t\te12.Tags = slices.Clone(v11.Tags)\n")
+}
+
+// 结构体新增字段后重新生成，Clone 自动覆盖新字段
+func TestGenerateAfterAddingField(t *testing.T) {
+	path := writeSource(t, "package p\ntype Hero struct {\n\tName string\n}\n")
+	src, err := generate(path, []string{"Hero"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(src), "Skills")
+
+	require.NoError(t, os.WriteFile(path, []byte("package p\ntype Hero struct {\n\tName string\n\tSkills []string\n}\n"), 0o644))
+	src, err = generate(path, []string{"Hero"})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "\tc.Skills = slices.Clone(x.Skills)\n")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"missing", "package p\n", "type T not found"},
+		{"not struct", "package p\ntype T int\n", "T is not a struct"},
+		{"generic", "package p\ntype T[E any] struct{ V E }\n", "generic type T is not supported"},
+		{"bad tag", "package p\ntype T struct{ V []int `clone:\"deep\"` }\n", `T.V: unknown clone tag "deep"`},
+		{"recursive pointer", "package p\ntype T struct{ Root *Node }\ntype Node struct{ Children []*Node }\n",
+			"T.Root.Children: recursive type Node must be listed in -type"},
+		{"recursive value", "package p\ntype T struct{ Root Node }\ntype Node struct{ Children []Node }\n",
+			"T.Root.Children: recursive type Node must be listed in -type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(writeSource(t, tt.src), []string{"T"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+// 递归的类型列在 -type 中时调用它自己的 Clone
+func TestGenerateRecursive(t *testing.T) {
+	path := writeSource(t, "package p\ntype Tree struct{ Root Node }\ntype Node struct {\n\tChildren []Node\n\tIndex    map[string]Node\n}\n")
+	src, err := generate(path, []string{"Tree", "Node"})
+	require.NoError(t, err)
+	out := string(src)
+	assert.Contains(t, out, "\t\tc.Root.Children[i1] = *x.Root.Children[i1].Clone()\n")
+	assert.Contains(t, out, "\t\tc.Children[i1] = *x.Children[i1].Clone()\n")
+	assert.Contains(t, out, "\t\t\te5 = *v4.Clone()\n")
+}
+
+func TestRun(t *testing.T) {
+	path := writeSource(t, "package p\ntype Point struct{ X, Y int }\ntype Line struct{ A, B *Point }\n")
+	var stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"-type", "Line,Point"}, path, &stderr), stderr.String())
+	src, err := os.ReadFile(filepath.Join(filepath.Dir(path), "line_clone.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "\tc.A = x.A.Clone()\n")
+
+	assert.Equal(t, 2, run(nil, path, &stderr))
+	assert.Equal(t, 1, run([]string{"-type", "Missing"}, path, &stderr))
+	assert.Contains(t, stderr.String(), "clonegen: ")
+}
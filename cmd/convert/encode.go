@@ -0,0 +1,513 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/qiye45/go_design_pattern/creational/factory/parser"
+)
+
+// UnrepresentableError 值无法在目标格式中表示
+type UnrepresentableError struct {
+	Format string
+	Path   string // 形如 $.servers[0].port
+	Reason string
+}
+
+func (e *UnrepresentableError) Error() string {
+	return fmt.Sprintf("cannot represent %s in %s: %s", e.Path, e.Format, e.Reason)
+}
+
+// normalize 把无序的 map 转为按键排序的 MapSlice，输出函数只需要处理 MapSlice
+func normalize(v any) any {
+	switch v := v.(type) {
+	case parser.MapSlice:
+		for i := range v {
+			v[i].Value = normalize(v[i].Value)
+		}
+		return v
+	case map[string]any:
+		m := make(parser.MapSlice, 0, len(v))
+		for k, e := range v {
+			m = append(m, parser.MapItem{Key: k, Value: normalize(e)})
+		}
+		slices.SortFunc(m, func(a, b parser.MapItem) int { return strings.Compare(a.Key, b.Key) })
+		return m
+	case []map[string]any:
+		list := make([]any, len(v))
+		for i, e := range v {
+			list[i] = normalize(e)
+		}
+		return list
+	case []any:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+		return v
+	}
+	return v
+}
+
+func keyPath(path, key string) string     { return path + "." + key }
+func indexPath(path string, i int) string { return fmt.Sprintf("%s[%d]", path, i) }
+
+// quoteString 输出 JSON 风格的双引号字符串，TOML 的基本字符串也兼容这种转义
+func quoteString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// ================== JSON ==================
+
+func writeJSON(v any, pretty bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, v, "$", 0, pretty); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func encodeJSON(buf *bytes.Buffer, v any, path string, depth int, pretty bool) error {
+	newline := func(d int) {
+		if pretty {
+			buf.WriteByte('\n')
+			buf.WriteString(strings.Repeat("  ", d))
+		}
+	}
+	switch v := v.(type) {
+	case parser.MapSlice:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteByte('{')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(depth + 1)
+			buf.WriteString(quoteString(item.Key))
+			buf.WriteByte(':')
+			if pretty {
+				buf.WriteByte(' ')
+			}
+			if err := encodeJSON(buf, item.Value, keyPath(path, item.Key), depth+1, pretty); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		buf.WriteByte('}')
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(depth + 1)
+			if err := encodeJSON(buf, e, indexPath(path, i), depth+1, pretty); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		buf.WriteByte(']')
+	case string:
+		buf.WriteString(quoteString(v))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return &UnrepresentableError{Format: "json", Path: path, Reason: fmt.Sprintf("%v is not a valid JSON number", v)}
+		}
+		b, _ := json.Marshal(v)
+		buf.Write(b)
+	case time.Time:
+		buf.WriteString(quoteString(v.Format(time.RFC3339Nano)))
+	default:
+		s, err := scalarString(v)
+		if err != nil {
+			return &UnrepresentableError{Format: "json", Path: path, Reason: err.Error()}
+		}
+		if v == nil {
+			s = "null"
+		}
+		buf.WriteString(s)
+	}
+	return nil
+}
+
+// scalarString 数字和布尔值的文本形式
+func scalarString(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("unsupported value of type %T", v)
+}
+
+// ================== YAML ==================
+
+func writeYAML(v any, pretty bool) ([]byte, error) {
+	n, err := yamlNode(v, "$", !pretty)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func yamlNode(v any, path string, flow bool) (*yaml.Node, error) {
+	var style yaml.Style
+	if flow {
+		style = yaml.FlowStyle
+	}
+	switch v := v.(type) {
+	case parser.MapSlice:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: style}
+		for _, item := range v {
+			c, err := yamlNode(item.Value, keyPath(path, item.Key), flow)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item.Key}, c)
+		}
+		return n, nil
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: style}
+		for i, e := range v {
+			c, err := yamlNode(e, indexPath(path, i), flow)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, c)
+		}
+		return n, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	case json.Number:
+		if isInteger(v.String()) {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: v.String()}, nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: v.String()}, nil
+	case float64:
+		var s string
+		switch {
+		case math.IsNaN(v):
+			s = ".nan"
+		case math.IsInf(v, 1):
+			s = ".inf"
+		case math.IsInf(v, -1):
+			s = "-.inf"
+		default:
+			s = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: s}, nil
+	case time.Time:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: v.Format(time.RFC3339Nano)}, nil
+	}
+	s, err := scalarString(v)
+	if err != nil {
+		return nil, &UnrepresentableError{Format: "yaml", Path: path, Reason: err.Error()}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: s}, nil
+}
+
+func isInteger(s string) bool { return !strings.ContainsAny(s, ".eE") }
+
+// ================== TOML ==================
+
+var bareKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(k string) string {
+	if bareKeyRe.MatchString(k) {
+		return k
+	}
+	return quoteString(k)
+}
+
+func writeTOML(v any, pretty bool) ([]byte, error) {
+	m, ok := v.(parser.MapSlice)
+	if !ok {
+		return nil, &UnrepresentableError{Format: "toml", Path: "$", Reason: "top-level value must be a table"}
+	}
+	w := &tomlWriter{pretty: pretty}
+	if err := w.table(m, nil, "$"); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+type tomlWriter struct {
+	buf    bytes.Buffer
+	pretty bool
+}
+
+// isTableArray 非空且所有元素都是表的数组写成 [[x]]
+func isTableArray(v any) bool {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return false
+	}
+	for _, e := range list {
+		if _, ok := e.(parser.MapSlice); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// table 先写本表的键值，再写子表和数组表（TOML 要求子表出现在键值之后）
+func (w *tomlWriter) table(m parser.MapSlice, keys []string, path string) error {
+	for _, item := range m {
+		if _, ok := item.Value.(parser.MapSlice); ok || isTableArray(item.Value) {
+			continue
+		}
+		s, err := w.inline(item.Value, keyPath(path, item.Key))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&w.buf, "%s = %s\n", tomlKey(item.Key), s)
+	}
+	for _, item := range m {
+		sub := append(slices.Clip(keys), tomlKey(item.Key))
+		switch v := item.Value.(type) {
+		case parser.MapSlice:
+			w.header("[" + strings.Join(sub, ".") + "]")
+			if err := w.table(v, sub, keyPath(path, item.Key)); err != nil {
+				return err
+			}
+		case []any:
+			if !isTableArray(v) {
+				continue
+			}
+			for i, e := range v {
+				w.header("[[" + strings.Join(sub, ".") + "]]")
+				if err := w.table(e.(parser.MapSlice), sub, indexPath(keyPath(path, item.Key), i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (w *tomlWriter) header(h string) {
+	if w.pretty && w.buf.Len() > 0 {
+		w.buf.WriteByte('\n')
+	}
+	w.buf.WriteString(h)
+	w.buf.WriteByte('\n')
+}
+
+// inline 数组和内联表中的值
+func (w *tomlWriter) inline(v any, path string) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", &UnrepresentableError{Format: "toml", Path: path, Reason: "toml has no null value"}
+	case string:
+		return quoteString(v), nil
+	case parser.MapSlice:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := w.inline(item.Value, keyPath(path, item.Key))
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, tomlKey(item.Key)+" = "+s)
+		}
+		if len(parts) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for i, e := range v {
+			s, err := w.inline(e, indexPath(path, i))
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case json.Number:
+		if isInteger(v.String()) {
+			if _, err := strconv.ParseInt(v.String(), 10, 64); err != nil {
+				return "", &UnrepresentableError{Format: "toml", Path: path, Reason: fmt.Sprintf("integer %s overflows int64", v)}
+			}
+			return v.String(), nil
+		}
+		return v.String(), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return "", &UnrepresentableError{Format: "toml", Path: path, Reason: fmt.Sprintf("integer %d overflows int64", v)}
+		}
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "nan", nil
+		case math.IsInf(v, 1):
+			return "inf", nil
+		case math.IsInf(v, -1):
+			return "-inf", nil
+		}
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if isInteger(s) {
+			s += ".0" // TOML 的浮点数必须带小数部分或指数
+		}
+		return s, nil
+	}
+	s, err := scalarString(v)
+	if err != nil {
+		return "", &UnrepresentableError{Format: "toml", Path: path, Reason: err.Error()}
+	}
+	return s, nil
+}
+
+// ================== INI ==================
+
+func writeINI(v any, pretty bool) ([]byte, error) {
+	m, ok := v.(parser.MapSlice)
+	if !ok {
+		return nil, &UnrepresentableError{Format: "ini", Path: "$", Reason: "top-level value must be a mapping"}
+	}
+	sep := "="
+	if pretty {
+		sep = " = "
+	}
+	var buf bytes.Buffer
+	// 顶层的标量写在所有 section 之前
+	for _, item := range m {
+		if _, ok := item.Value.(parser.MapSlice); ok {
+			continue
+		}
+		path := keyPath("$", item.Key)
+		if err := iniKey(item.Key, path); err != nil {
+			return nil, err
+		}
+		s, err := iniValue(item.Value, path)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "%s%s%s\n", item.Key, sep, s)
+	}
+	for _, item := range m {
+		section, ok := item.Value.(parser.MapSlice)
+		if !ok {
+			continue
+		}
+		if err := iniSectionName(item.Key, keyPath("$", item.Key)); err != nil {
+			return nil, err
+		}
+		if pretty && buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "[%s]\n", item.Key)
+		for _, e := range section {
+			path := keyPath(keyPath("$", item.Key), e.Key)
+			if _, ok := e.Value.(parser.MapSlice); ok {
+				return nil, &UnrepresentableError{Format: "ini", Path: path, Reason: "ini sections cannot be nested"}
+			}
+			if err := iniKey(e.Key, path); err != nil {
+				return nil, err
+			}
+			s, err := iniValue(e.Value, path)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&buf, "%s%s%s\n", e.Key, sep, s)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// iniKey 检查键能否原样解析回来：分隔符、换行、首尾空白会改变键，以 [ ; # 开头会被当成 section 或注释
+func iniKey(key, path string) error {
+	switch {
+	case key == "" || key != strings.TrimSpace(key):
+		return &UnrepresentableError{Format: "ini", Path: path, Reason: "ini keys cannot be empty or have surrounding spaces"}
+	case strings.ContainsAny(key, "=:[\r\n"):
+		return &UnrepresentableError{Format: "ini", Path: path, Reason: `ini keys cannot contain '=', ':', '[' or newlines`}
+	case key[0] == ';' || key[0] == '#':
+		return &UnrepresentableError{Format: "ini", Path: path, Reason: "ini keys cannot start with a comment character"}
+	}
+	return nil
+}
+
+// iniSectionName 检查 section 名能否原样解析回来
+func iniSectionName(name, path string) error {
+	if name == "" || name != strings.TrimSpace(name) || strings.ContainsAny(name, "]\r\n") {
+		return &UnrepresentableError{Format: "ini", Path: path, Reason: `ini section names cannot be empty, have surrounding spaces or contain ']' or newlines`}
+	}
+	return nil
+}
+
+func iniValue(v any, path string) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", &UnrepresentableError{Format: "ini", Path: path, Reason: "ini has no null value"}
+	case []any:
+		return "", &UnrepresentableError{Format: "ini", Path: path, Reason: "ini has no arrays"}
+	case string:
+		// 首尾空白和注释符号会在解析时丢失，首尾成对的引号会被去掉，都需要再加一层引号
+		// 解析时只去掉最外层的一对引号，所以不需要转义
+		if v != strings.TrimSpace(v) || strings.ContainsAny(v, ";#\n") || isQuoted(v) {
+			if strings.Contains(v, "\n") {
+				return "", &UnrepresentableError{Format: "ini", Path: path, Reason: "ini values cannot span lines"}
+			}
+			return `"` + v + `"`, nil
+		}
+		return v, nil
+	}
+	s, err := scalarString(v)
+	if err != nil {
+		return "", &UnrepresentableError{Format: "ini", Path: path, Reason: err.Error()}
+	}
+	return s, nil
+}
+
+// isQuoted 与 ini 解析器的 unquote 判断一致
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]
+}
@@ -0,0 +1,133 @@
+// convert 基于 factory_method 中的解析器工厂，在 JSON / YAML / TOML / INI 之间互相转换
+//
+//	convert -to yaml config.json
+//	cat config.yaml | convert -from yaml -to json -compact
+//
+// 输入格式默认按文件后缀或内容识别，能保序的格式会保持原有的键顺序；
+// 某个值无法在目标格式中表示时（例如 TOML 里的 null、INI 里的数组），以非 0 状态退出并指出值所在路径
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/qiye45/go_design_pattern/creational/factory/factory_method"
+	"github.com/qiye45/go_design_pattern/creational/factory/parser"
+)
+
+// factories 格式到解析器工厂的映射
+var factories = map[string]factory_method.Factory{
+	parser.FormatJSON: factory_method.JsonFactory{},
+	parser.FormatYAML: factory_method.YamlFactory{},
+	parser.FormatTOML: factory_method.TomlFactory{},
+	parser.FormatINI:  factory_method.IniFactory{},
+}
+
+// writers 格式到输出函数的映射
+var writers = map[string]func(v any, pretty bool) ([]byte, error){
+	parser.FormatJSON: writeJSON,
+	parser.FormatYAML: writeYAML,
+	parser.FormatTOML: writeTOML,
+	parser.FormatINI:  writeINI,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	from := fs.String("from", "", "输入格式 json|yaml|toml|ini，默认按文件后缀或内容识别")
+	to := fs.String("to", "", "输出格式 json|yaml|toml|ini（必填）")
+	compact := fs.Bool("compact", false, "紧凑输出，默认美化输出")
+	out := fs.String("o", "", "输出文件，默认标准输出")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: convert -to FORMAT [-from FORMAT] [-compact] [-o FILE] [FILE]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *to == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	name, data, err := readInput(fs.Arg(0), stdin)
+	if err == nil {
+		var b []byte
+		if b, err = convert(name, data, *from, *to, !*compact); err == nil {
+			err = writeOutput(*out, b, stdout)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "convert:", err)
+		return 1
+	}
+	return 0
+}
+
+func readInput(name string, stdin io.Reader) (string, string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if name == "" || name == "-" {
+		b, err = io.ReadAll(stdin)
+		name = ""
+	} else {
+		b, err = os.ReadFile(name)
+	}
+	return name, string(b), err
+}
+
+func writeOutput(name string, b []byte, stdout io.Writer) error {
+	if name == "" {
+		_, err := stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(name, b, 0o644)
+}
+
+// convert 把 data 从 from 格式转换为 to 格式，from 为空时自动识别
+func convert(name, data, from, to string, pretty bool) ([]byte, error) {
+	from, to = normalizeFormat(from), normalizeFormat(to)
+	if from == "" {
+		detected, err := parser.Detect(name, "", data)
+		if err != nil {
+			var de *parser.DetectError
+			if errors.As(err, &de) && len(de.Candidates) > 1 {
+				return nil, fmt.Errorf("%w; use -from to choose one", err)
+			}
+			return nil, fmt.Errorf("%w; use -from to set the input format", err)
+		}
+		from = detected
+	}
+	f, ok := factories[from]
+	if !ok {
+		return nil, fmt.Errorf("unsupported input format %q", from)
+	}
+	write, ok := writers[to]
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format %q", to)
+	}
+
+	v, _, err := parser.ParseOrdered(f.Create(), data)
+	if err != nil {
+		return nil, err
+	}
+	return write(normalize(v), pretty)
+}
+
+func normalizeFormat(f string) string {
+	f = strings.ToLower(f)
+	if f == "yml" {
+		return parser.FormatYAML
+	}
+	return f
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonDoc = `{"name":"app","port":8080,"ratio":0.5,"server":{"host":"localhost","tags":["a","b"]},"debug":true}`
+
+func TestConvertJSONToYAML(t *testing.T) {
+	out, err := convert("", jsonDoc, "", "yaml", true)
+	require.NoError(t, err)
+	assert.Equal(t, `name: app
+port: 8080
+ratio: 0.5
+server:
+  host: localhost
+  tags:
+    - a
+    - b
+debug: true
+`, string(out))
+
+	out, err = convert("", jsonDoc, "json", "yml", false)
+	require.NoError(t, err)
+	assert.Equal(t, "{name: app, port: 8080, ratio: 0.5, server: {host: localhost, tags: [a, b]}, debug: true}\n", string(out))
+}
+
+func TestConvertYAMLToJSON(t *testing.T) {
+	doc := "zeta: 1\nalpha:\n  - x: \"yes\"\n  - null\n"
+	out, err := convert("a.yaml", doc, "", "json", true)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "zeta": 1,
+  "alpha": [
+    {
+      "x": "yes"
+    },
+    null
+  ]
+}
+`, string(out))
+
+	out, err = convert("a.yaml", doc, "", "json", false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"zeta":1,"alpha":[{"x":"yes"},null]}`+"\n", string(out))
+}
+
+func TestConvertToTOML(t *testing.T) {
+	doc := `{"title":"t","owner":{"name":"n"},"servers":[{"ip":"10.0.0.1"},{"ip":"10.0.0.2"}],"n":3.0,"mixed":[1,{"a":1}]}`
+	out, err := convert("", doc, "json", "toml", true)
+	require.NoError(t, err)
+	assert.Equal(t, `title = "t"
+n = 3.0
+mixed = [1, { a = 1 }]
+
+[owner]
+name = "n"
+
+[[servers]]
+ip = "10.0.0.1"
+
+[[servers]]
+ip = "10.0.0.2"
+`, string(out))
+
+	// 转回 JSON 后内容不变
+	back, err := convert("", string(out), "toml", "json", false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"title":"t","n":3,"mixed":[1,{"a":1}],"owner":{"name":"n"},"servers":[{"ip":"10.0.0.1"},{"ip":"10.0.0.2"}]}`+"\n", string(back))
+}
+
+func TestConvertINI(t *testing.T) {
+	out, err := convert("", `{"name":"app","db":{"host":"127.0.0.1","note":" padded "}}`, "json", "ini", true)
+	require.NoError(t, err)
+	assert.Equal(t, "name = app\n\n[db]\nhost = 127.0.0.1\nnote = \" padded \"\n", string(out))
+
+	// 值本身带引号时再加一层，解析后保持原样
+	doc := `{"a":"\"quoted\"","b":"'single'","c":"\"","d":"x\"y"}`
+	out, err = convert("", doc, "json", "ini", false)
+	require.NoError(t, err)
+	assert.Equal(t, "a=\"\"quoted\"\"\nb=\"'single'\"\nc=\"\nd=x\"y\n", string(out))
+	back, err := convert("", string(out), "ini", "json", false)
+	require.NoError(t, err)
+	assert.JSONEq(t, doc, string(back))
+
+	out, err = convert("app.ini", "name=app\n[db]\nport=3306\n", "", "yaml", true)
+	require.NoError(t, err)
+	assert.Equal(t, "name: app\ndb:\n  port: \"3306\"\n", string(out))
+}
+
+func TestConvertUnrepresentable(t *testing.T) {
+	cases := []struct {
+		doc, to, path string
+	}{
+		{`{"a":{"b":null}}`, "toml", "$.a.b"},
+		{`[1,2]`, "toml", "$"},
+		{`{"list":[1,2]}`, "ini", "$.list"},
+		{`{"a":{"b":{"c":1}}}`, "ini", "$.a.b"},
+		{`{"a=b":1}`, "ini", "$.a=b"},
+		{`{"a:b":1}`, "ini", "$.a:b"},
+		{`{"[a":1}`, "ini", "$.[a"},
+		{`{"s":{"k[0]":1}}`, "ini", "$.s.k[0]"},
+		{`{"#a":1}`, "ini", "$.#a"},
+		{`{" a":1}`, "ini", "$. a"},
+		{`{"a]":{"k":1}}`, "ini", "$.a]"},
+		{`{"a\nb":{"k":1}}`, "ini", "$.a\nb"},
+		{`{"n":18446744073709551616}`, "toml", "$.n"},
+	}
+	for _, c := range cases {
+		_, err := convert("", c.doc, "json", c.to, true)
+		var ue *UnrepresentableError
+		require.True(t, errors.As(err, &ue), "%s -> %s: %v", c.doc, c.to, err)
+		assert.Equal(t, c.path, ue.Path)
+		assert.Equal(t, c.to, ue.Format)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-to", "json", "-compact"}, strings.NewReader("b: 1\na: 2\n"), &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, `{"b":1,"a":2}`+"\n", stdout.String())
+
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.toml"), filepath.Join(dir, "out.json")
+	require.NoError(t, os.WriteFile(in, []byte("x = 1\n"), 0o644))
+	code = run([]string{"-to", "json", "-compact", "-o", out, in}, nil, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, `{"x":1}`+"\n", string(b))
+
+	stderr.Reset()
+	code = run([]string{"-to", "toml"}, strings.NewReader(`{"a":null}`), &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "convert: cannot represent $.a in toml: toml has no null value\n", stderr.String())
+
+	stderr.Reset()
+	code = run([]string{"-to", "json"}, strings.NewReader("port = 80\n"), &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), "candidates: toml, ini")
+
+	assert.Equal(t, 2, run(nil, nil, &stdout, &stderr))
+}
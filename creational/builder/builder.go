@@ -1,21 +1,35 @@
-package main
+package builder
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+//go:generate go run ../../cmd/buildergen -type Computer -steps CPU,Memory
 
 // Computer 产品：电脑
 type Computer struct {
-	CPU    string
-	Memory int
-	Disk   int
-	GPU    string
+	CPU    string `json:"cpu" yaml:"cpu"`
+	Memory Size   `json:"memory" yaml:"memory"`
+	Disk   Size   `json:"disk" yaml:"disk"`
+	GPU    string `json:"gpu" yaml:"gpu"`
+	PSU    int    `json:"psu" yaml:"psu"` // 电源功率 W
 }
 
 // ComputerBuilder Builder
 type ComputerBuilder struct {
 	cpu    string
-	memory int
-	disk   int
+	memory Size
+	disk   Size
 	gpu    string
+	psu    int
+	rules  []Rule
+	errs   []Violation // 带单位的 setter 解析失败的记录，在 Build 时报告
+
+	catalog   *Catalog
+	memoryKit string
+	diskModel string
 }
 
 // NewComputerBuilder 链式调用设置参数
@@ -24,43 +38,160 @@ func (b *ComputerBuilder) SetCPU(cpu string) *ComputerBuilder {
 	b.cpu = cpu
 	return b
 }
-func (b *ComputerBuilder) SetMemory(m int) *ComputerBuilder {
+
+// SetMemory 设置内存容量，同时丢弃之前 SetMemoryString 留下的解析错误
+func (b *ComputerBuilder) SetMemory(m Size) *ComputerBuilder {
 	b.memory = m
+	b.clearErr("memory")
 	return b
 }
-func (b *ComputerBuilder) SetDisk(d int) *ComputerBuilder {
+
+// SetDisk 设置硬盘容量，同时丢弃之前 SetDiskString 留下的解析错误
+func (b *ComputerBuilder) SetDisk(d Size) *ComputerBuilder {
 	b.disk = d
+	b.clearErr("disk")
+	return b
+}
+
+// SetMemoryString 接受带单位的容量，如 "32GB"，解析失败时在 Build 时报告
+func (b *ComputerBuilder) SetMemoryString(s string) *ComputerBuilder {
+	return b.setSize("memory", &b.memory, s)
+}
+
+// SetDiskString 接受带单位的容量，如 "1TB"，解析失败时在 Build 时报告
+func (b *ComputerBuilder) SetDiskString(s string) *ComputerBuilder {
+	return b.setSize("disk", &b.disk, s)
+}
+
+func (b *ComputerBuilder) setSize(field string, dst *Size, s string) *ComputerBuilder {
+	b.clearErr(field)
+	v, err := ParseSize(s)
+	if err != nil {
+		b.errs = append(b.errs, Violation{Field: field, Message: err.Error()})
+		return b
+	}
+	*dst = v
 	return b
 }
+
+// clearErr 字段被重新设置后，之前的解析错误不再有效
+func (b *ComputerBuilder) clearErr(field string) {
+	b.errs = slices.DeleteFunc(b.errs, func(v Violation) bool { return v.Field == field })
+}
 func (b *ComputerBuilder) SetGPU(g string) *ComputerBuilder {
 	b.gpu = g
 	return b
 }
+func (b *ComputerBuilder) SetPSU(w int) *ComputerBuilder {
+	b.psu = w
+	return b
+}
 
-// Build 构建最终对象
-func (b *ComputerBuilder) Build() Computer {
-	fmt.Println("开始构建电脑...")
-	if b.cpu == "" {
-		b.cpu = "Intel i7"
-	}
-	if b.memory == 0 {
-		b.memory = 16
+// AddRule 追加自定义校验规则，与 DefaultRules 一起在 Build 时检查
+func (b *ComputerBuilder) AddRule(r Rule) *ComputerBuilder {
+	b.rules = append(b.rules, r)
+	return b
+}
+
+// FromComputer 以已有的电脑为起点创建 builder，用于在其基础上派生变体
+func FromComputer(c Computer) *ComputerBuilder {
+	return &ComputerBuilder{cpu: c.CPU, memory: c.Memory, disk: c.Disk, gpu: c.GPU, psu: c.PSU}
+}
+
+// Clone 复制 builder（包括自定义规则），修改副本不影响原 builder
+func (b *ComputerBuilder) Clone() *ComputerBuilder {
+	c := *b
+	c.rules = slices.Clone(b.rules)
+	c.errs = slices.Clone(b.errs)
+	return &c
+}
+
+// UseCatalog 按配件目录解析配件名称：检查兼容性和功耗，并可以通过 BOM 生成物料清单
+// 使用目录后由目录的功耗检查代替 GPURules
+func (b *ComputerBuilder) UseCatalog(cat *Catalog) *ComputerBuilder {
+	b.catalog = cat
+	return b
+}
+
+// SetMemoryKit 指定目录中的内存型号，未设置内存容量时使用该型号的容量
+func (b *ComputerBuilder) SetMemoryKit(name string) *ComputerBuilder {
+	b.memoryKit = name
+	return b
+}
+
+// SetDiskModel 指定目录中的硬盘型号，未设置硬盘容量时使用该型号的容量
+func (b *ComputerBuilder) SetDiskModel(name string) *ComputerBuilder {
+	b.diskModel = name
+	return b
+}
+
+// Build 为未设置的可选字段填充默认值，然后按规则校验
+// 默认值只写入返回的 Computer，不修改 builder，因此同一个 builder 可以重复 Build，也可以被多个 goroutine 同时 Build
+// 配置不合法时返回 *ValidationError，列出所有违反的规则
+func (b *ComputerBuilder) Build() (Computer, error) {
+	c, _, err := b.build()
+	return c, err
+}
+
+// BOM 构建并返回物料清单，需要先调用 UseCatalog
+func (b *ComputerBuilder) BOM() (BOM, error) {
+	if b.catalog == nil {
+		return BOM{}, errors.New("builder: BOM requires a catalog, call UseCatalog first")
 	}
-	if b.disk == 0 {
-		b.disk = 512
+	c, lines, err := b.build()
+	if err != nil {
+		return BOM{}, err
 	}
-	if b.gpu == "" {
-		b.gpu = "RTX 3080"
+	bom := BOM{Computer: c, Lines: lines}
+	for _, l := range lines {
+		bom.TotalPrice += l.Price
+		bom.PowerDraw += l.Wattage
 	}
-	return Computer{CPU: b.cpu, Memory: b.memory, Disk: b.disk, GPU: b.gpu}
+	return bom, nil
 }
 
-func main() {
-	// 灵活选择参数，链式调用
-	pc := NewComputerBuilder().
-		SetCPU("Intel i9").
-		SetMemory(32).
-		Build()
+func (b *ComputerBuilder) build() (Computer, []BOMLine, error) {
+	c := Computer{CPU: b.cpu, Memory: b.memory, Disk: b.disk, GPU: b.gpu, PSU: b.psu}
+	if c.Memory == 0 {
+		c.Memory = b.capacity(KindMemory, b.memoryKit, 16)
+	}
+	if c.Disk == 0 {
+		c.Disk = b.capacity(KindDisk, b.diskModel, 512)
+	}
+	if c.GPU == "" {
+		c.GPU = "RTX 3080"
+	}
+	if c.PSU == 0 {
+		c.PSU = 750
+	}
 
-	fmt.Printf("电脑配置: %+v\n", pc)
+	violations := slices.Clone(b.errs)
+	var lines []BOMLine
+	if b.catalog == nil {
+		violations = append(violations, check(c, DefaultRules)...)
+		for _, p := range []struct{ field, name string }{{"memory", b.memoryKit}, {"disk", b.diskModel}} {
+			if p.name != "" {
+				violations = append(violations, Violation{Field: p.field, Message: fmt.Sprintf("part %q requires a catalog", p.name)})
+			}
+		}
+	} else {
+		var vs []Violation
+		lines, vs = b.catalog.resolve(c, b.memoryKit, b.diskModel)
+		violations = append(append(violations, check(c, FieldRules)...), vs...)
+	}
+	violations = append(violations, check(c, b.rules)...)
+	if err := validationError(violations); err != nil {
+		return Computer{}, nil, err
+	}
+	return c, lines, nil
+}
+
+// capacity 指定了目录中的型号时返回其容量，否则返回默认值
+func (b *ComputerBuilder) capacity(kind Kind, name string, def Size) Size {
+	if b.catalog != nil && name != "" {
+		if part, ok := b.catalog.Lookup(kind, name); ok {
+			return part.Capacity
+		}
+	}
+	return def
 }
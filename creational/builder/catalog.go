@@ -0,0 +1,251 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/qiye45/go_design_pattern/creational/factory/parser"
+)
+
+// Kind 配件类别
+type Kind string
+
+const (
+	KindCPU    Kind = "cpu"
+	KindGPU    Kind = "gpu"
+	KindMemory Kind = "memory"
+	KindDisk   Kind = "disk"
+)
+
+// PowerLoad 配件总功耗不能超过电源额定功率的这个比例
+const PowerLoad = 0.8
+
+// Component 目录中的一个配件
+type Component struct {
+	Kind      Kind     `json:"-" yaml:"-"`
+	Name      string   `json:"name" yaml:"name"`
+	Price     int      `json:"price" yaml:"price"`         // 元
+	Wattage   int      `json:"wattage" yaml:"wattage"`     // 功耗 W
+	Interface string   `json:"interface" yaml:"interface"` // 内存、硬盘、显卡使用的接口，如 DDR5、NVMe、PCIe 4.0，为空表示不占用接口
+	Capacity  Size     `json:"capacity" yaml:"capacity"`   // 内存、硬盘容量
+	Supports  []string `json:"supports" yaml:"supports"`   // CPU 平台支持的接口
+}
+
+// Catalog 配件目录
+type Catalog struct {
+	parts map[Kind][]Component
+}
+
+// catalogFile 目录文件的结构，每个类别一个列表
+type catalogFile struct {
+	CPU    []Component `json:"cpu" yaml:"cpu"`
+	GPU    []Component `json:"gpu" yaml:"gpu"`
+	Memory []Component `json:"memory" yaml:"memory"`
+	Disk   []Component `json:"disk" yaml:"disk"`
+}
+
+// LoadCatalog 读取目录文件（YAML/JSON），格式按后缀或内容识别
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f catalogFile
+	if err := (&parser.AutoParser{Filename: path}).Unmarshal(string(data), &f); err != nil {
+		return nil, fmt.Errorf("load catalog %s: %w", path, err)
+	}
+	// 按文件中类别的固定顺序展开，错误信息的顺序才是确定的
+	var parts []Component
+	for _, group := range []struct {
+		kind Kind
+		list []Component
+	}{{KindCPU, f.CPU}, {KindGPU, f.GPU}, {KindMemory, f.Memory}, {KindDisk, f.Disk}} {
+		for _, c := range group.list {
+			c.Kind = group.kind
+			parts = append(parts, c)
+		}
+	}
+	cat, err := NewCatalog(parts...)
+	if err != nil {
+		return nil, fmt.Errorf("load catalog %s: %w", path, err)
+	}
+	return cat, nil
+}
+
+// NewCatalog 检查每个配件的属性是否完整，同一类别下名称不能重复
+func NewCatalog(parts ...Component) (*Catalog, error) {
+	cat := &Catalog{parts: make(map[Kind][]Component)}
+	var errs []error
+	for _, c := range parts {
+		if msg := c.problem(); msg != "" {
+			errs = append(errs, fmt.Errorf("%s %q: %s", c.Kind, c.Name, msg))
+			continue
+		}
+		if _, dup := cat.Lookup(c.Kind, c.Name); dup {
+			errs = append(errs, fmt.Errorf("%s %q: defined more than once", c.Kind, c.Name))
+			continue
+		}
+		c.Supports = slices.Clone(c.Supports)
+		cat.parts[c.Kind] = append(cat.parts[c.Kind], c)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	for _, list := range cat.parts {
+		slices.SortFunc(list, func(a, b Component) int { return strings.Compare(a.Name, b.Name) })
+	}
+	return cat, nil
+}
+
+func (c Component) problem() string {
+	switch {
+	case c.Name == "":
+		return "name is required"
+	case c.Price < 0 || c.Wattage < 0:
+		return "price and wattage must not be negative"
+	}
+	switch c.Kind {
+	case KindCPU:
+		if len(c.Supports) == 0 {
+			return "supports is required"
+		}
+	case KindMemory, KindDisk:
+		if c.Interface == "" || c.Capacity <= 0 {
+			return "interface and capacity are required"
+		}
+	case KindGPU:
+	default:
+		return "unknown kind"
+	}
+	return ""
+}
+
+// Lookup 按类别和名称查找配件
+func (cat *Catalog) Lookup(kind Kind, name string) (Component, bool) {
+	for _, c := range cat.parts[kind] {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Component{}, false
+}
+
+// Parts 某个类别下的所有配件，按名称排序
+func (cat *Catalog) Parts(kind Kind) []Component { return slices.Clone(cat.parts[kind]) }
+
+// BOMLine 物料清单中的一行
+type BOMLine struct {
+	Component
+	Field string // 对应 Computer 的字段
+}
+
+// BOM 物料清单
+type BOM struct {
+	Computer   Computer
+	Lines      []BOMLine
+	TotalPrice int // 元
+	PowerDraw  int // 所有配件功耗之和 W
+}
+
+// WriteTo 以表格形式输出物料清单
+func (b BOM) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tPART\tPRICE\tPOWER")
+	for _, l := range b.Lines {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%dW\n", l.Kind, l.Name, l.Price, l.Wattage)
+	}
+	fmt.Fprintf(tw, "total\t\t%d\t%dW\n", b.TotalPrice, b.PowerDraw)
+	fmt.Fprintf(tw, "psu\t\t\t%dW\n", b.Computer.PSU)
+	err := tw.Flush()
+	return cw.n, err
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// resolve 把 Computer 中的配件名称和容量对应到目录中的配件，并检查兼容性和功耗
+// memoryKit、diskModel 为空时选择容量一致、接口兼容的最便宜型号
+func (cat *Catalog) resolve(c Computer, memoryKit, diskModel string) ([]BOMLine, []Violation) {
+	var (
+		lines      []BOMLine
+		violations []Violation
+	)
+	fail := func(field, format string, args ...any) {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	cpu, ok := cat.Lookup(KindCPU, c.CPU)
+	if !ok {
+		fail("cpu", "unknown part %q in catalog", c.CPU)
+	} else {
+		lines = append(lines, BOMLine{Component: cpu, Field: "cpu"})
+	}
+	// compatible 配件接口是否被 CPU 平台支持；CPU 未知时不再重复报错
+	compatible := func(part Component) bool {
+		return !ok || part.Interface == "" || slices.Contains(cpu.Supports, part.Interface)
+	}
+
+	if gpu, found := cat.Lookup(KindGPU, c.GPU); !found {
+		fail("gpu", "unknown part %q in catalog", c.GPU)
+	} else if !compatible(gpu) {
+		fail("gpu", "%s uses %s, not supported by %s", gpu.Name, gpu.Interface, cpu.Name)
+	} else {
+		lines = append(lines, BOMLine{Component: gpu, Field: "gpu"})
+	}
+
+	for _, p := range []struct {
+		field    string
+		kind     Kind
+		name     string
+		capacity Size
+	}{{"memory", KindMemory, memoryKit, c.Memory}, {"disk", KindDisk, diskModel, c.Disk}} {
+		if p.name != "" {
+			part, found := cat.Lookup(p.kind, p.name)
+			switch {
+			case !found:
+				fail(p.field, "unknown part %q in catalog", p.name)
+			case part.Capacity != p.capacity:
+				fail(p.field, "%s has %s, want %s", part.Name, part.Capacity, p.capacity)
+			case !compatible(part):
+				fail(p.field, "%s uses %s, not supported by %s", part.Name, part.Interface, cpu.Name)
+			default:
+				lines = append(lines, BOMLine{Component: part, Field: p.field})
+			}
+			continue
+		}
+		var best *Component
+		for _, part := range cat.parts[p.kind] {
+			if part.Capacity == p.capacity && compatible(part) && (best == nil || part.Price < best.Price) {
+				best = &part
+			}
+		}
+		if best == nil {
+			fail(p.field, "no compatible %s %s in catalog", p.capacity, p.kind)
+		} else {
+			lines = append(lines, BOMLine{Component: *best, Field: p.field})
+		}
+	}
+
+	draw := 0
+	for _, l := range lines {
+		draw += l.Wattage
+	}
+	if limit := int(float64(c.PSU) * PowerLoad); draw > limit {
+		fail("psu", "parts draw %dW, a %dW psu supports at most %dW", draw, c.PSU, limit)
+	}
+	return lines, violations
+}
@@ -0,0 +1,152 @@
+package builder
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	cat, err := LoadCatalog("testdata/catalog.yaml")
+	require.NoError(t, err)
+	return cat
+}
+
+func ExampleBOM_WriteTo() {
+	cat, _ := LoadCatalog("testdata/catalog.yaml")
+	bom, _ := NewComputerBuilder().
+		UseCatalog(cat).
+		SetCPU("AMD 7950X").
+		SetGPU("RTX 4090").
+		SetPSU(850).
+		SetDiskModel("Samsung 990 Pro 2TB").
+		BOM()
+	bom.WriteTo(os.Stdout)
+	// Output:
+	// KIND    PART                      PRICE  POWER
+	// cpu     AMD 7950X                 3999   170W
+	// gpu     RTX 4090                  12999  450W
+	// memory  Kingston Fury 2x8GB DDR5  399    6W
+	// disk    Samsung 990 Pro 2TB       1299   8W
+	// total                             18696  634W
+	// psu                                      850W
+}
+
+func TestCatalogLookup(t *testing.T) {
+	cat := loadTestCatalog(t)
+	cpu, ok := cat.Lookup(KindCPU, "Intel i5-12400")
+	require.True(t, ok)
+	assert.Equal(t, Component{Kind: KindCPU, Name: "Intel i5-12400", Price: 999, Wattage: 65,
+		Supports: []string{"DDR4", "NVMe", "SATA", "PCIe 4.0"}}, cpu)
+	_, ok = cat.Lookup(KindGPU, "AMD 7950X")
+	assert.False(t, ok)
+
+	var names []string
+	for _, c := range cat.Parts(KindGPU) {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"RTX 3080", "RTX 4090", "RTX 5090", "集成显卡"}, names)
+}
+
+func TestNewCatalogValidates(t *testing.T) {
+	_, err := NewCatalog(
+		Component{Kind: KindCPU, Name: "X1"},
+		Component{Kind: KindMemory, Name: "M1", Interface: "DDR5"},
+		Component{Kind: KindGPU, Name: "G1", Price: -1},
+		Component{Kind: KindGPU, Name: "G2"},
+		Component{Kind: KindGPU, Name: "G2"},
+		Component{Kind: "fan", Name: "F1"},
+		Component{Kind: KindDisk},
+	)
+	assert.EqualError(t, err, `cpu "X1": supports is required
+memory "M1": interface and capacity are required
+gpu "G1": price and wattage must not be negative
+gpu "G2": defined more than once
+fan "F1": unknown kind
+disk "": name is required`)
+}
+
+func TestCatalogResolve(t *testing.T) {
+	cat := loadTestCatalog(t)
+
+	// 不指定型号时选择容量一致、接口兼容的最便宜型号
+	bom, err := NewComputerBuilder().UseCatalog(cat).SetCPU("Intel i5-12400").SetGPU("集成显卡").SetPSU(300).BOM()
+	require.NoError(t, err)
+	var parts []string
+	for _, l := range bom.Lines {
+		parts = append(parts, l.Field+"="+l.Name)
+	}
+	assert.Equal(t, []string{"cpu=Intel i5-12400", "gpu=集成显卡", "memory=Crucial 2x8GB DDR4", "disk=WD Blue 512GB"}, parts)
+	assert.Equal(t, 999+259+299, bom.TotalPrice)
+	assert.Equal(t, 65+5+4, bom.PowerDraw)
+
+	// 指定型号时使用型号的容量
+	pc, err := NewComputerBuilder().UseCatalog(cat).SetCPU("AMD 7950X").SetMemoryKit("Kingston Fury 2x16GB DDR5").Build()
+	require.NoError(t, err)
+	assert.Equal(t, 32*GB, pc.Memory)
+}
+
+func TestCatalogRejectsIncompatibleParts(t *testing.T) {
+	cat := loadTestCatalog(t)
+	_, err := NewComputerBuilder().
+		UseCatalog(cat).
+		SetCPU("Intel i5-12400").
+		SetGPU("RTX 5090").
+		SetMemory(64).
+		SetDiskModel("Samsung 990 Pro 2TB").
+		SetDisk(1024).
+		SetPSU(200).
+		BOM()
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, []Violation{
+		{Field: "gpu", Message: "RTX 5090 uses PCIe 5.0, not supported by Intel i5-12400"},
+		{Field: "memory", Message: "no compatible 64GB memory in catalog"},
+		{Field: "disk", Message: "Samsung 990 Pro 2TB has 2TB, want 1TB"},
+	}, ve.Violations)
+
+	_, err = NewComputerBuilder().UseCatalog(cat).SetCPU("AMD 7950X").SetGPU("RTX 4090").SetPSU(750).SetMemoryKit("Crucial 2x8GB DDR4").Build()
+	assert.EqualError(t, err, "invalid computer: memory: Crucial 2x8GB DDR4 uses DDR4, not supported by AMD 7950X; "+
+		"psu: parts draw 624W, a 750W psu supports at most 600W")
+
+	_, err = NewComputerBuilder().UseCatalog(cat).SetCPU("Pentium 4").SetGPU("Voodoo 3").Build()
+	assert.EqualError(t, err, `invalid computer: cpu: unknown part "Pentium 4" in catalog; gpu: unknown part "Voodoo 3" in catalog`)
+}
+
+func TestPartsRequireCatalog(t *testing.T) {
+	_, err := NewComputerBuilder().SetCPU("AMD 7950X").SetMemoryKit("Kingston Fury 2x16GB DDR5").Build()
+	assert.EqualError(t, err, `invalid computer: memory: part "Kingston Fury 2x16GB DDR5" requires a catalog`)
+
+	_, err = NewComputerBuilder().SetCPU("AMD 7950X").BOM()
+	assert.EqualError(t, err, "builder: BOM requires a catalog, call UseCatalog first")
+}
+
+func TestLoadCatalogJSON(t *testing.T) {
+	path := t.TempDir() + "/catalog.json"
+	require.NoError(t, os.WriteFile(path, []byte(`{"cpu":[{"name":"AMD 7950X","supports":["DDR5"]}],"gpu":[{"name":"RTX 4090","wattage":450}]}`), 0o644))
+	cat, err := LoadCatalog(path)
+	require.NoError(t, err)
+	gpu, ok := cat.Lookup(KindGPU, "RTX 4090")
+	require.True(t, ok)
+	assert.Equal(t, 450, gpu.Wattage)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"cpu":[{"name":"AMD 7950X"}]}`), 0o644))
+	_, err = LoadCatalog(path)
+	require.Error(t, err)
+	assert.True(t, strings.HasSuffix(err.Error(), `cpu "AMD 7950X": supports is required`), err.Error())
+
+	// 错误按 cpu、gpu、memory、disk 的顺序排列，与文件中的顺序无关
+	require.NoError(t, os.WriteFile(path, []byte(`{"disk":[{"name":"D"}],"memory":[{"name":"M"}],"gpu":[{"name":"G","price":-1}],"cpu":[{"name":"C"}]}`), 0o644))
+	for range 10 {
+		_, err = LoadCatalog(path)
+		assert.EqualError(t, err, "load catalog "+path+`: cpu "C": supports is required
+gpu "G": price and wattage must not be negative
+memory "M": interface and capacity are required
+disk "D": interface and capacity are required`)
+	}
+}
@@ -0,0 +1,73 @@
+// Code generated by buildergen -type Computer -steps CPU,Memory; DO NOT EDIT.
+
+package builder
+
+import (
+	"fmt"
+)
+
+// ComputerCPUStep 第 1 步，必须设置 CPU
+type ComputerCPUStep interface {
+	SetCPU(v string) ComputerMemoryStep
+}
+
+// ComputerMemoryStep 第 2 步，必须设置 Memory
+type ComputerMemoryStep interface {
+	SetMemory(v Size) ComputerFinalStep
+}
+
+// ComputerFinalStep 必填步骤已完成，可以设置可选字段并构建
+type ComputerFinalStep interface {
+	SetDisk(v Size) ComputerFinalStep
+	SetGPU(v string) ComputerFinalStep
+	SetPSU(v int) ComputerFinalStep
+	Build() (Computer, error)
+}
+
+// NewComputerStepBuilder 按 CPU、Memory 的顺序设置必填字段后才能调用 Build，默认值和校验与 ComputerBuilder 相同，
+// 必填字段设置为零值时 Build 返回错误
+func NewComputerStepBuilder() ComputerCPUStep {
+	return computerStepBuilder{b: NewComputerBuilder()}
+}
+
+// computerStepBuilder 每次调用都返回修改过的副本，不改变调用它的阶段值
+type computerStepBuilder struct {
+	b    *ComputerBuilder
+	zero [2]bool // 各必填字段是否被设置为零值
+}
+
+func (s computerStepBuilder) SetCPU(v string) ComputerMemoryStep {
+	s.zero[0] = v == ""
+	s.b = s.b.Clone().SetCPU(v)
+	return s
+}
+
+func (s computerStepBuilder) SetMemory(v Size) ComputerFinalStep {
+	s.zero[1] = v == 0
+	s.b = s.b.Clone().SetMemory(v)
+	return s
+}
+
+func (s computerStepBuilder) SetDisk(v Size) ComputerFinalStep {
+	s.b = s.b.Clone().SetDisk(v)
+	return s
+}
+
+func (s computerStepBuilder) SetGPU(v string) ComputerFinalStep {
+	s.b = s.b.Clone().SetGPU(v)
+	return s
+}
+
+func (s computerStepBuilder) SetPSU(v int) ComputerFinalStep {
+	s.b = s.b.Clone().SetPSU(v)
+	return s
+}
+
+func (s computerStepBuilder) Build() (Computer, error) {
+	for i, step := range [...]string{"CPU", "Memory"} {
+		if s.zero[i] {
+			return Computer{}, fmt.Errorf("computer: required step %s must not be the zero value", step)
+		}
+	}
+	return s.b.Build()
+}
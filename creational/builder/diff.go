@@ -0,0 +1,157 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// ChangeKind 变更类型
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"    // 新增：map 中新增的键或切片中新增的元素
+	Removed  ChangeKind = "removed"  // 删除：map 中删除的键或切片中删除的元素
+	Modified ChangeKind = "modified" // 修改：字段的值变化，包括由零值变为非零值和反过来
+)
+
+// Change 一处字段级变更
+type Change struct {
+	Path string     `json:"path"` // 字段路径，优先使用 json 标签，如 "memory"、"lines[2].price"
+	Kind ChangeKind `json:"kind"`
+	Old  any        `json:"old,omitempty"`
+	New  any        `json:"new,omitempty"`
+}
+
+// Changes 两个值之间的全部变更，按字段顺序排列
+type Changes []Change
+
+// Diff 用 go-cmp 比较 old 和 new，返回字段级的变更；T 不能包含未导出字段
+func Diff[T any](old, new T) Changes {
+	r := &diffReporter{}
+	cmp.Equal(old, new, cmp.Reporter(r))
+	return r.changes
+}
+
+type diffReporter struct {
+	path    cmp.Path
+	changes Changes
+}
+
+func (r *diffReporter) PushStep(ps cmp.PathStep) { r.path = append(r.path, ps) }
+func (r *diffReporter) PopStep()                 { r.path = r.path[:len(r.path)-1] }
+
+func (r *diffReporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+	vx, vy := r.path.Last().Values()
+	c := Change{Path: pathString(r.path), Kind: Modified}
+	switch {
+	case !vx.IsValid():
+		c.Kind = Added
+	case !vy.IsValid():
+		c.Kind = Removed
+	}
+	if c.Kind != Added {
+		c.Old = vx.Interface()
+	}
+	if c.Kind != Removed {
+		c.New = vy.Interface()
+	}
+	r.changes = append(r.changes, c)
+}
+
+// pathString 把 cmp.Path 转换成 a.b[0][key] 形式，嵌入字段不出现在路径中
+func pathString(path cmp.Path) string {
+	var b strings.Builder
+	for i, step := range path {
+		switch s := step.(type) {
+		case cmp.StructField:
+			parent := path[i-1].Type()
+			for parent.Kind() == reflect.Pointer {
+				parent = parent.Elem()
+			}
+			if f, ok := parent.FieldByName(s.Name()); ok && f.Anonymous {
+				continue
+			}
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(fieldName(parent, s.Name()))
+		case cmp.SliceIndex:
+			kx, ky := s.SplitKeys()
+			k := kx
+			if k < 0 {
+				k = ky
+			}
+			b.WriteString("[" + strconv.Itoa(k) + "]")
+		case cmp.MapIndex:
+			fmt.Fprintf(&b, "[%v]", s.Key())
+		}
+	}
+	return b.String()
+}
+
+// fieldName 有 json 标签时使用标签中的名称
+func fieldName(t reflect.Type, name string) string {
+	f, _ := t.FieldByName(name)
+	if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" && tag != "-" {
+		return tag
+	}
+	return name
+}
+
+// WriteText 每行一处变更：+ 新增、- 删除、~ 修改
+func (cs Changes) WriteText(w io.Writer) error {
+	for _, c := range cs {
+		var err error
+		switch c.Kind {
+		case Added:
+			_, err = fmt.Fprintf(w, "+ %s: %v\n", c.Path, c.New)
+		case Removed:
+			_, err = fmt.Fprintf(w, "- %s: %v\n", c.Path, c.Old)
+		default:
+			_, err = fmt.Fprintf(w, "~ %s: %v -> %v\n", c.Path, c.Old, c.New)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON 输出 JSON 数组，没有变更时输出 []
+func (cs Changes) WriteJSON(w io.Writer) error {
+	if cs == nil {
+		cs = Changes{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cs)
+}
+
+// WriteMarkdown 输出 Markdown 表格
+func (cs Changes) WriteMarkdown(w io.Writer) error {
+	if _, err := io.WriteString(w, "| Field | Change | Old | New |\n| --- | --- | --- | --- |\n"); err != nil {
+		return err
+	}
+	cell := func(v any) string {
+		if v == nil {
+			return ""
+		}
+		s := strings.ReplaceAll(fmt.Sprint(v), "|", `\|`)
+		return strings.ReplaceAll(s, "\n", "<br>")
+	}
+	for _, c := range cs {
+		if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s |\n", cell(c.Path), c.Kind, cell(c.Old), cell(c.New)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
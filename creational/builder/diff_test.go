@@ -0,0 +1,120 @@
+package builder
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleDiff() {
+	old := Computer{CPU: "AMD 7950X", Memory: 32 * GB, Disk: TB, GPU: "RTX 4090", PSU: 1000}
+	upgraded, _ := FromComputer(old).SetMemory(64).SetDisk(2 * TB).Build()
+
+	Diff(old, upgraded).WriteText(os.Stdout)
+	// Output:
+	// ~ memory: 32GB -> 64GB
+	// ~ disk: 1TB -> 2TB
+}
+
+func TestDiffKinds(t *testing.T) {
+	old := Computer{CPU: "Intel i5", Memory: 16 * GB, Disk: 512 * GB, PSU: 400}
+	cur := Computer{Memory: 32 * GB, Disk: 512 * GB, GPU: "RTX 4070", PSU: 650}
+	assert.Equal(t, Changes{
+		{Path: "cpu", Kind: Modified, Old: "Intel i5", New: ""},
+		{Path: "memory", Kind: Modified, Old: 16 * GB, New: 32 * GB},
+		{Path: "gpu", Kind: Modified, Old: "", New: "RTX 4070"},
+		{Path: "psu", Kind: Modified, Old: 400, New: 650},
+	}, Diff(old, cur))
+	assert.Empty(t, Diff(old, old))
+
+	// 结构体字段由零值变为非零值或反过来都是修改，不是新增或删除
+	assert.Equal(t, Changes{{Path: "psu", Kind: Modified, Old: 0, New: 650}}, Diff(Computer{}, Computer{PSU: 650}))
+	assert.Equal(t, Changes{{Path: "psu", Kind: Modified, Old: 650, New: 0}}, Diff(Computer{PSU: 650}, Computer{}))
+}
+
+func TestDiffBOM(t *testing.T) {
+	cat := loadTestCatalog(t)
+	b := NewComputerBuilder().UseCatalog(cat).SetCPU("AMD 7950X").SetGPU("集成显卡").SetPSU(300)
+	before, err := b.BOM()
+	require.NoError(t, err)
+	after, err := b.Clone().SetGPU("RTX 4090").SetPSU(850).SetDisk(0).SetDiskModel("Samsung 990 Pro 2TB").BOM()
+	require.NoError(t, err)
+
+	// 集成显卡的价格和功耗为 0，换成独立显卡后同样报告为修改
+	var paths []string
+	for _, c := range Diff(before, after) {
+		paths = append(paths, string(c.Kind)+" "+c.Path)
+	}
+	assert.Equal(t, []string{
+		"modified Computer.disk",
+		"modified Computer.gpu",
+		"modified Computer.psu",
+		"modified Lines[1].name",
+		"modified Lines[1].price",
+		"modified Lines[1].wattage",
+		"modified Lines[1].interface",
+		// 硬盘换了型号，大部分字段都不同，cmp 按删除旧元素、新增新元素报告
+		"removed Lines[3]",
+		"added Lines[3]",
+		"modified TotalPrice",
+		"modified PowerDraw",
+	}, paths)
+
+	// 切片长度变化时报告新增或删除的元素
+	short := before
+	short.Lines = before.Lines[:2]
+	changes := Diff(before, short)
+	require.Len(t, changes, 2)
+	assert.Equal(t, Change{Path: "Lines[2]", Kind: Removed, Old: before.Lines[2]}, changes[0])
+	assert.Equal(t, Change{Path: "Lines[3]", Kind: Removed, Old: before.Lines[3]}, changes[1])
+}
+
+func TestDiffMap(t *testing.T) {
+	changes := Diff(map[string]Size{"a": GB, "b": 2 * GB}, map[string]Size{"b": 3 * GB, "c": TB})
+	assert.Equal(t, Changes{
+		{Path: "[a]", Kind: Removed, Old: GB},
+		{Path: "[b]", Kind: Modified, Old: 2 * GB, New: 3 * GB},
+		{Path: "[c]", Kind: Added, New: TB},
+	}, changes)
+}
+
+func TestChangesRender(t *testing.T) {
+	changes := Diff(
+		Computer{CPU: "Intel i5", Memory: 16 * GB, GPU: "RTX 3080"},
+		Computer{CPU: "AMD 7950X", Memory: 32 * GB, GPU: "RTX 4090 | Ti", PSU: 850},
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, changes.WriteText(&buf))
+	assert.Equal(t, `~ cpu: Intel i5 -> AMD 7950X
+~ memory: 16GB -> 32GB
+~ gpu: RTX 3080 -> RTX 4090 | Ti
+~ psu: 0 -> 850
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, changes.WriteJSON(&buf))
+	assert.JSONEq(t, `[
+		{"path": "cpu", "kind": "modified", "old": "Intel i5", "new": "AMD 7950X"},
+		{"path": "memory", "kind": "modified", "old": "16GB", "new": "32GB"},
+		{"path": "gpu", "kind": "modified", "old": "RTX 3080", "new": "RTX 4090 | Ti"},
+		{"path": "psu", "kind": "modified", "old": 0, "new": 850}
+	]`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, changes.WriteMarkdown(&buf))
+	assert.Equal(t, `| Field | Change | Old | New |
+| --- | --- | --- | --- |
+| cpu | modified | Intel i5 | AMD 7950X |
+| memory | modified | 16GB | 32GB |
+| gpu | modified | RTX 3080 | RTX 4090 \| Ti |
+| psu | modified | 0 | 850 |
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, Changes(nil).WriteJSON(&buf))
+	assert.Equal(t, "[]\n", buf.String())
+}
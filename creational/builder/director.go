@@ -0,0 +1,166 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/qiye45/go_design_pattern/creational/factory/parser"
+)
+
+// Preset 命名的预设配置，零值字段在 Build 时使用默认值
+type Preset struct {
+	Name   string `json:"-" yaml:"-" toml:"-"`
+	CPU    string `json:"cpu" yaml:"cpu" toml:"cpu"`
+	Memory Size   `json:"memory" yaml:"memory" toml:"memory"`
+	Disk   Size   `json:"disk" yaml:"disk" toml:"disk"`
+	GPU    string `json:"gpu" yaml:"gpu" toml:"gpu"`
+	PSU    int    `json:"psu" yaml:"psu" toml:"psu"`
+}
+
+// presetKeys 预设文件中允许出现的键
+var presetKeys = map[string]bool{"cpu": true, "memory": true, "disk": true, "gpu": true, "psu": true}
+
+// Apply 把预设中设置了的字段写入 builder
+func (p Preset) Apply(b *ComputerBuilder) *ComputerBuilder {
+	if p.CPU != "" {
+		b.SetCPU(p.CPU)
+	}
+	if p.Memory != 0 {
+		b.SetMemory(p.Memory)
+	}
+	if p.Disk != 0 {
+		b.SetDisk(p.Disk)
+	}
+	if p.GPU != "" {
+		b.SetGPU(p.GPU)
+	}
+	if p.PSU != 0 {
+		b.SetPSU(p.PSU)
+	}
+	return b
+}
+
+// BuiltinPresets 内置预设：gaming、office、server
+func BuiltinPresets() []Preset {
+	return []Preset{
+		{Name: "gaming", CPU: "AMD 7800X3D", Memory: 32, Disk: 2048, GPU: "RTX 4090", PSU: 1000},
+		{Name: "office", CPU: "Intel i5", Memory: 16, Disk: 512, GPU: "集成显卡", PSU: 400},
+		{Name: "server", CPU: "AMD EPYC 9654", Memory: 512, Disk: 16384, GPU: "集成显卡", PSU: 1200},
+	}
+}
+
+// LoadPresets 读取预设文件，文件内容是预设名到配置的映射，格式按后缀或内容识别（YAML/JSON/TOML）
+// 未知的键视为错误，避免拼写错误被静默忽略；返回的预设按名称排序
+func LoadPresets(path string) ([]Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser.AutoParser{Filename: path}
+	raw, err := p.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("load presets %s: %w", path, err)
+	}
+	top, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("load presets %s: want a mapping of preset name to config, got %T", path, raw)
+	}
+	var errs []error
+	for _, name := range sortedNames(top) {
+		fields, ok := top[name].(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Errorf("preset %q: want a mapping, got %T", name, top[name]))
+			continue
+		}
+		for _, k := range sortedNames(fields) {
+			if !presetKeys[k] {
+				errs = append(errs, fmt.Errorf("preset %q: unknown key %q", name, k))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("load presets %s: %w", path, err)
+	}
+
+	var m map[string]Preset
+	if err := p.Unmarshal(string(data), &m); err != nil {
+		return nil, fmt.Errorf("load presets %s: %w", path, err)
+	}
+	presets := make([]Preset, 0, len(m))
+	for _, name := range sortedNames(m) {
+		preset := m[name]
+		preset.Name = name
+		presets = append(presets, preset)
+	}
+	return presets, nil
+}
+
+// Director 按预设指挥 builder 构建产品
+type Director struct {
+	presets map[string]Preset
+}
+
+// NewDirector 加载预设时逐个试构建，名称为空、重复或构建失败的预设都会报错
+func NewDirector(presets ...Preset) (*Director, error) {
+	d := &Director{presets: make(map[string]Preset, len(presets))}
+	var errs []error
+	for _, p := range presets {
+		switch _, dup := d.presets[p.Name]; {
+		case p.Name == "":
+			errs = append(errs, errors.New("preset with empty name"))
+			continue
+		case dup:
+			errs = append(errs, fmt.Errorf("preset %q: defined more than once", p.Name))
+			continue
+		}
+		if _, err := p.Apply(NewComputerBuilder()).Build(); err != nil {
+			errs = append(errs, fmt.Errorf("preset %q: %w", p.Name, err))
+		}
+		d.presets[p.Name] = p
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Presets 所有预设的名称
+func (d *Director) Presets() []string { return sortedNames(d.presets) }
+
+// Preset 按名称查找预设
+func (d *Director) Preset(name string) (Preset, bool) {
+	p, ok := d.presets[name]
+	return p, ok
+}
+
+// Builder 返回已应用预设的 builder，调用方可以继续链式覆盖个别字段
+func (d *Director) Builder(name string) (*ComputerBuilder, error) {
+	p, ok := d.presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q, available: %v", name, d.Presets())
+	}
+	return p.Apply(NewComputerBuilder()), nil
+}
+
+// Construct 应用预设，再依次执行 overrides 覆盖个别字段，最后构建
+func (d *Director) Construct(name string, overrides ...func(*ComputerBuilder)) (Computer, error) {
+	b, err := d.Builder(name)
+	if err != nil {
+		return Computer{}, err
+	}
+	for _, o := range overrides {
+		o(b)
+	}
+	return b.Build()
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,75 @@
+package builder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleDirector() {
+	d, _ := NewDirector(BuiltinPresets()...)
+	fmt.Println(d.Presets())
+
+	pc, err := d.Construct("gaming", func(b *ComputerBuilder) { b.SetMemory(64) })
+	fmt.Printf("%+v %v\n", pc, err)
+	// Output:
+	// [gaming office server]
+	// {CPU:AMD 7800X3D Memory:64GB Disk:2TB GPU:RTX 4090 PSU:1000} <nil>
+}
+
+func TestDirectorBuilder(t *testing.T) {
+	d, err := NewDirector(BuiltinPresets()...)
+	require.NoError(t, err)
+
+	b, err := d.Builder("office")
+	require.NoError(t, err)
+	pc, err := b.SetDisk(1024).Build()
+	require.NoError(t, err)
+	assert.Equal(t, Computer{CPU: "Intel i5", Memory: 16, Disk: 1024, GPU: "集成显卡", PSU: 400}, pc)
+
+	// 覆盖导致配置不合法时同样返回校验错误
+	_, err = d.Construct("office", func(b *ComputerBuilder) { b.SetGPU("RTX 4090") })
+	assert.EqualError(t, err, "invalid computer: psu: 400W is not enough for RTX 4090, need at least 850W")
+
+	_, err = d.Construct("nas")
+	assert.EqualError(t, err, `unknown preset "nas", available: [gaming office server]`)
+}
+
+func TestNewDirectorValidates(t *testing.T) {
+	_, err := NewDirector(
+		Preset{Name: "cheap", CPU: "Intel i3", GPU: "RTX 4090", PSU: 450},
+		Preset{CPU: "Intel i5"},
+		Preset{Name: "office", CPU: "Intel i5"},
+		Preset{Name: "office", CPU: "Intel i7"},
+		Preset{Name: "nocpu"},
+	)
+	assert.EqualError(t, err, `preset "cheap": invalid computer: psu: 450W is not enough for RTX 4090, need at least 850W
+preset with empty name
+preset "office": defined more than once
+preset "nocpu": invalid computer: cpu: is required`)
+}
+
+func TestLoadPresets(t *testing.T) {
+	presets, err := LoadPresets("testdata/presets.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []Preset{
+		{Name: "htpc", CPU: "Intel i3", GPU: "集成显卡", PSU: 300},
+		{Name: "workstation", CPU: "Intel i9", Memory: 128, GPU: "RTX 4080", PSU: 850},
+	}, presets)
+
+	more, err := LoadPresets("testdata/presets.json")
+	require.NoError(t, err)
+	d, err := NewDirector(append(append(BuiltinPresets(), presets...), more...)...)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gaming", "htpc", "office", "render", "server", "workstation"}, d.Presets())
+
+	pc, err := d.Construct("workstation")
+	require.NoError(t, err)
+	assert.Equal(t, Computer{CPU: "Intel i9", Memory: 128, Disk: 512, GPU: "RTX 4080", PSU: 850}, pc)
+
+	_, err = LoadPresets("testdata/presets_invalid.yaml")
+	assert.EqualError(t, err, `load presets testdata/presets_invalid.yaml: preset "broken": want a mapping, got int
+preset "typo": unknown key "memroy"`)
+}
@@ -0,0 +1,170 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Example() {
+	// 灵活选择参数，链式调用
+	pc, err := NewComputerBuilder().
+		SetCPU("Intel i9").
+		SetMemory(32).
+		Build()
+
+	fmt.Printf("电脑配置: %+v %v\n", pc, err)
+	// Output: 电脑配置: {CPU:Intel i9 Memory:32GB Disk:512GB GPU:RTX 3080 PSU:750} <nil>
+}
+
+func TestBuildValidation(t *testing.T) {
+	_, err := NewComputerBuilder().
+		SetMemory(-8).
+		SetDisk(100000).
+		SetGPU("RTX 4090").
+		SetPSU(650).
+		Build()
+
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, []Violation{
+		{Field: "cpu", Message: "is required"},
+		{Field: "memory", Message: "must be between 1 and 2048, got -8"},
+		{Field: "disk", Message: "must be between 32 and 65536, got 100000"},
+		{Field: "psu", Message: "650W is not enough for RTX 4090, need at least 850W"},
+	}, ve.Violations)
+	assert.Equal(t, "invalid computer: cpu: is required; memory: must be between 1 and 2048, got -8; "+
+		"disk: must be between 32 and 65536, got 100000; psu: 650W is not enough for RTX 4090, need at least 850W", err.Error())
+}
+
+func TestBuildCustomRule(t *testing.T) {
+	noIntel := Rule{Field: "cpu", Check: func(c Computer) string {
+		if c.CPU == "Intel i9" {
+			return "out of stock"
+		}
+		return ""
+	}}
+	_, err := NewComputerBuilder().SetCPU("Intel i9").SetGPU("GTX 1060").AddRule(noIntel).Build()
+	assert.EqualError(t, err, `invalid computer: gpu: unknown gpu "GTX 1060"; cpu: out of stock`)
+
+	pc, err := NewComputerBuilder().SetCPU("AMD 7950X").SetGPU("RTX 4090").SetPSU(1000).AddRule(noIntel).Build()
+	require.NoError(t, err)
+	assert.Equal(t, Computer{CPU: "AMD 7950X", Memory: 16, Disk: 512, GPU: "RTX 4090", PSU: 1000}, pc)
+}
+
+func ExampleServerBuilder() {
+	s, err := NewServerBuilder().
+		SetName("db-1").
+		AddDisks("hdd").
+		SetLabelsEntry("role", "db").
+		EditNetwork(func(n *Network) { n.Port = 5432 }).
+		Build()
+
+	fmt.Println(s.Name, s.Cores, s.Disks, s.Labels, s.Network.Port, *s.Replicas, s.Timeout, err)
+	// Output: db-1 8 [ssd ssd hdd] map[role:db] 5432 1 30s <nil>
+}
+
+func TestServerBuilderValidation(t *testing.T) {
+	_, err := NewServerBuilder().
+		SetCores(0).
+		SetReplicas(-1).
+		AddValidator(func(s Server) error {
+			if len(s.Disks) > 2 {
+				return errors.New("disks: at most 2")
+			}
+			return nil
+		}).
+		AddDisks("hdd").
+		Build()
+	assert.EqualError(t, err, "name: is required\ncores: must be positive\nreplicas: must not be negative\ndisks: at most 2")
+}
+
+func TestServerBuilderNoAliasing(t *testing.T) {
+	b := NewServerBuilder().SetName("web").SetLabelsEntry("env", "prod")
+	s1, err := b.Build()
+	require.NoError(t, err)
+
+	s1.Disks[0] = "nvme"
+	s1.Labels["env"] = "dev"
+	*s1.Replicas = 3
+
+	s2, err := b.Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ssd", "ssd"}, s2.Disks)
+	assert.Equal(t, map[string]string{"env": "prod"}, s2.Labels)
+	assert.Equal(t, 1, *s2.Replicas)
+}
+
+func TestBuildDoesNotMutateBuilder(t *testing.T) {
+	b := NewComputerBuilder().SetCPU("Intel i5")
+	before := *b
+	_, err := b.Build()
+	require.NoError(t, err)
+	assert.Equal(t, before, *b)
+
+	// 默认显卡没有写回 builder，换成集成显卡后默认电源功率依然可用
+	pc, err := b.SetGPU("集成显卡").SetPSU(300).Build()
+	require.NoError(t, err)
+	assert.Equal(t, Computer{CPU: "Intel i5", Memory: 16, Disk: 512, GPU: "集成显卡", PSU: 300}, pc)
+}
+
+func TestCloneAndFromComputer(t *testing.T) {
+	base := NewComputerBuilder().SetCPU("AMD 7950X").AddRule(Required("gpu", func(c Computer) string { return c.GPU }))
+	variant := base.Clone().SetGPU("RTX 4090").SetPSU(1000).AddRule(IntRange("memory", 32, 2048, func(c Computer) int { return int(c.Memory) }))
+
+	pc, err := base.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "RTX 3080", pc.GPU)
+	assert.Len(t, base.rules, 1)
+
+	_, err = variant.Build()
+	assert.EqualError(t, err, "invalid computer: memory: must be between 32 and 2048, got 16")
+
+	office := Computer{CPU: "Intel i5", Memory: 8, Disk: 256, GPU: "集成显卡", PSU: 300}
+	upgraded, err := FromComputer(office).SetMemory(32).Build()
+	require.NoError(t, err)
+	assert.Equal(t, Computer{CPU: "Intel i5", Memory: 32, Disk: 256, GPU: "集成显卡", PSU: 300}, upgraded)
+	assert.Equal(t, 8*GB, office.Memory)
+}
+
+func TestConcurrentBuild(t *testing.T) {
+	b := NewComputerBuilder().SetCPU("Intel i9")
+	want, err := b.Build()
+	require.NoError(t, err)
+
+	results := make(chan Computer, 16)
+	for range 16 {
+		go func() {
+			pc, _ := b.Build()
+			results <- pc
+		}()
+	}
+	for range 16 {
+		assert.Equal(t, want, <-results)
+	}
+}
+
+func TestServerBuilderCloneAndFrom(t *testing.T) {
+	base := NewServerBuilder().SetName("web").SetLabelsEntry("env", "prod")
+	clone := base.Clone().AddDisks("hdd").SetLabelsEntry("env", "dev").SetReplicas(3)
+
+	s, err := base.Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ssd", "ssd"}, s.Disks)
+	assert.Equal(t, "prod", s.Labels["env"])
+	assert.Equal(t, 1, *s.Replicas)
+
+	c, err := clone.Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ssd", "ssd", "hdd"}, c.Disks)
+	assert.Equal(t, "dev", c.Labels["env"])
+
+	v, err := FromServer(c).SetName("web-2").SetLabelsEntry("env", "test").Build()
+	require.NoError(t, err)
+	assert.Equal(t, "test", v.Labels["env"])
+	assert.Equal(t, "dev", c.Labels["env"])
+	assert.Equal(t, c.Disks, v.Disks)
+}
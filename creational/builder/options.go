@@ -0,0 +1,107 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Option 函数式选项，作用在 ComputerBuilder 上，可以返回错误
+type Option func(b *ComputerBuilder) error
+
+// NewComputer 用函数式选项构建电脑，默认值和校验规则与 ComputerBuilder 相同
+// 选项返回的错误会全部收集后一起返回，此时不再构建
+func NewComputer(opts ...Option) (Computer, error) {
+	b := NewComputerBuilder()
+	if err := b.Apply(opts...); err != nil {
+		return Computer{}, err
+	}
+	return b.Build()
+}
+
+// Apply 依次应用选项，便于和链式调用混用
+func (b *ComputerBuilder) Apply(opts ...Option) error {
+	var errs []error
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Options 把多个选项组合成一个，便于复用
+func Options(opts ...Option) Option {
+	return func(b *ComputerBuilder) error { return b.Apply(opts...) }
+}
+
+func WithCPU(cpu string) Option {
+	return func(b *ComputerBuilder) error { b.SetCPU(cpu); return nil }
+}
+
+func WithMemory(m Size) Option {
+	return func(b *ComputerBuilder) error { b.SetMemory(m); return nil }
+}
+
+func WithDisk(d Size) Option {
+	return func(b *ComputerBuilder) error { b.SetDisk(d); return nil }
+}
+
+func WithGPU(g string) Option {
+	return func(b *ComputerBuilder) error { b.SetGPU(g); return nil }
+}
+
+func WithPSU(w int) Option {
+	return func(b *ComputerBuilder) error { b.SetPSU(w); return nil }
+}
+
+// WithMemoryString 接受带单位的容量，如 "32GB"，解析失败时返回错误
+func WithMemoryString(s string) Option {
+	return func(b *ComputerBuilder) error {
+		m, err := ParseSize(s)
+		if err != nil {
+			return fmt.Errorf("memory: %w", err)
+		}
+		b.SetMemory(m)
+		return nil
+	}
+}
+
+// WithDiskString 接受带单位的容量，如 "1TB"，解析失败时返回错误
+func WithDiskString(s string) Option {
+	return func(b *ComputerBuilder) error {
+		d, err := ParseSize(s)
+		if err != nil {
+			return fmt.Errorf("disk: %w", err)
+		}
+		b.SetDisk(d)
+		return nil
+	}
+}
+
+// WithRule 追加自定义校验规则
+func WithRule(r Rule) Option {
+	return func(b *ComputerBuilder) error { b.AddRule(r); return nil }
+}
+
+// WithCatalog 按配件目录解析配件，见 UseCatalog
+func WithCatalog(cat *Catalog) Option {
+	return func(b *ComputerBuilder) error {
+		if cat == nil {
+			return errors.New("catalog: nil catalog")
+		}
+		b.UseCatalog(cat)
+		return nil
+	}
+}
+
+// WithPreset 应用 Director 中的预设，预设不存在时返回错误；写在后面的选项可以覆盖预设中的字段
+func WithPreset(d *Director, name string) Option {
+	return func(b *ComputerBuilder) error {
+		p, ok := d.Preset(name)
+		if !ok {
+			return fmt.Errorf("unknown preset %q, available: %v", name, d.Presets())
+		}
+		p.Apply(b)
+		return nil
+	}
+}
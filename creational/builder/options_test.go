@@ -0,0 +1,88 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNewComputer() {
+	pc, err := NewComputer(WithCPU("Intel i9"), WithMemory(32))
+	fmt.Printf("%+v %v\n", pc, err)
+	// Output: {CPU:Intel i9 Memory:32GB Disk:512GB GPU:RTX 3080 PSU:750} <nil>
+}
+
+func TestNewComputerMatchesBuilder(t *testing.T) {
+	fromOpts, err := NewComputer(WithCPU("AMD 7950X"), WithDiskString("2TB"), WithGPU("RTX 4090"), WithPSU(1000))
+	require.NoError(t, err)
+	fromBuilder, err := NewComputerBuilder().SetCPU("AMD 7950X").SetDisk(2 * TB).SetGPU("RTX 4090").SetPSU(1000).Build()
+	require.NoError(t, err)
+	assert.Equal(t, fromBuilder, fromOpts)
+
+	// 校验规则相同
+	_, err = NewComputer(WithMemory(-8))
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "invalid computer: cpu: is required; memory: must be between 1 and 2048, got -8", err.Error())
+}
+
+func TestOptionSets(t *testing.T) {
+	workstation := Options(WithCPU("Intel i9"), WithMemory(128), WithGPU("RTX 4080"), WithPSU(850))
+	bigDisk := Options(WithDisk(4 * TB))
+
+	pc, err := NewComputer(workstation, bigDisk)
+	require.NoError(t, err)
+	assert.Equal(t, Computer{CPU: "Intel i9", Memory: 128 * GB, Disk: 4 * TB, GPU: "RTX 4080", PSU: 850}, pc)
+
+	// 后面的选项覆盖前面的
+	pc, err = NewComputer(workstation, WithMemory(64))
+	require.NoError(t, err)
+	assert.Equal(t, 64*GB, pc.Memory)
+
+	d, err := NewDirector(BuiltinPresets()...)
+	require.NoError(t, err)
+	pc, err = NewComputer(WithPreset(d, "office"), WithDisk(TB))
+	require.NoError(t, err)
+	assert.Equal(t, Computer{CPU: "Intel i5", Memory: 16 * GB, Disk: TB, GPU: "集成显卡", PSU: 400}, pc)
+
+	// 和链式调用混用
+	b := NewComputerBuilder().SetGPU("RTX 4090")
+	require.NoError(t, b.Apply(workstation))
+	pc, err = b.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "RTX 4080", pc.GPU)
+}
+
+func TestOptionErrors(t *testing.T) {
+	d, err := NewDirector(BuiltinPresets()...)
+	require.NoError(t, err)
+	_, err = NewComputer(
+		WithCPU("Intel i9"),
+		WithMemoryString("lots"),
+		Options(WithDiskString("4PB"), WithPreset(d, "nas")),
+		WithCatalog(nil),
+	)
+	assert.EqualError(t, err, `memory: size "lots": invalid number
+disk: size "4PB": unknown unit "PB"
+unknown preset "nas", available: [gaming office server]
+catalog: nil catalog`)
+
+	// 选项出错时不会构建，因此不会混入校验错误
+	var ve *ValidationError
+	_, err = NewComputer(WithMemoryString("lots"))
+	assert.False(t, errors.As(err, &ve))
+
+	custom := errors.New("out of stock")
+	_, err = NewComputer(WithCPU("Intel i9"), func(*ComputerBuilder) error { return custom })
+	assert.ErrorIs(t, err, custom)
+}
+
+func TestOptionsWithCatalog(t *testing.T) {
+	cat := loadTestCatalog(t)
+	_, err := NewComputer(WithCatalog(cat), WithCPU("Intel i5-12400"), WithGPU("RTX 5090"), WithPSU(1000),
+		WithRule(Rule{Field: "cpu", Check: func(c Computer) string { return "discontinued" }}))
+	assert.EqualError(t, err, "invalid computer: gpu: RTX 5090 uses PCIe 5.0, not supported by Intel i5-12400; cpu: discontinued")
+}
@@ -0,0 +1,42 @@
+package builder
+
+import (
+	"errors"
+	"time"
+)
+
+//go:generate go run ../../cmd/buildergen -type Server
+//go:generate go run ../../cmd/buildergen -type Server -steps Name
+
+// Server 由 buildergen 生成 builder 的产品，见 server_builder.go
+type Server struct {
+	Name     string
+	Cores    int      `default:"8"`
+	Memory   int      `default:"32"` // GB
+	Disks    []string `default:"ssd,ssd"`
+	Labels   map[string]string
+	Network  Network
+	Replicas *int          `default:"1"`
+	Timeout  time.Duration `default:"30s"`
+}
+
+// Network 嵌套结构体
+type Network struct {
+	Host string
+	Port int
+}
+
+// Validate 生成的 Build 会自动调用
+func (s Server) Validate() error {
+	var errs []error
+	if s.Name == "" {
+		errs = append(errs, errors.New("name: is required"))
+	}
+	if s.Cores < 1 {
+		errs = append(errs, errors.New("cores: must be positive"))
+	}
+	if s.Replicas != nil && *s.Replicas < 0 {
+		errs = append(errs, errors.New("replicas: must not be negative"))
+	}
+	return errors.Join(errs...)
+}
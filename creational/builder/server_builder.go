@@ -0,0 +1,139 @@
+// Code generated by buildergen -type Server; DO NOT EDIT.
+
+package builder
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ServerBuilder Server 的 builder
+type ServerBuilder struct {
+	v          Server
+	validators []func(Server) error
+}
+
+// NewServerBuilder 创建 builder，并按 default 标签填充默认值
+func NewServerBuilder() *ServerBuilder {
+	b := &ServerBuilder{}
+	b.v.Cores = 8
+	b.v.Memory = 32
+	b.v.Disks = []string{"ssd", "ssd"}
+	b.v.Replicas = new(int)
+	*b.v.Replicas = int(1)
+	b.v.Timeout = 30 * time.Second
+	return b
+}
+
+// FromServer 以已有的 Server 为起点创建 builder，不填充默认值
+func FromServer(v Server) *ServerBuilder {
+	b := &ServerBuilder{v: v}
+	b.v.Disks = slices.Clone(v.Disks)
+	b.v.Labels = maps.Clone(v.Labels)
+	if v.Replicas != nil {
+		b.v.Replicas = new(int)
+		*b.v.Replicas = *v.Replicas
+	}
+	return b
+}
+
+// Clone 复制 builder，修改副本不影响原 builder
+func (b *ServerBuilder) Clone() *ServerBuilder {
+	c := &ServerBuilder{v: b.v, validators: slices.Clone(b.validators)}
+	c.v.Disks = slices.Clone(b.v.Disks)
+	c.v.Labels = maps.Clone(b.v.Labels)
+	if b.v.Replicas != nil {
+		c.v.Replicas = new(int)
+		*c.v.Replicas = *b.v.Replicas
+	}
+	return c
+}
+
+func (b *ServerBuilder) SetName(v string) *ServerBuilder {
+	b.v.Name = v
+	return b
+}
+
+func (b *ServerBuilder) SetCores(v int) *ServerBuilder {
+	b.v.Cores = v
+	return b
+}
+
+func (b *ServerBuilder) SetMemory(v int) *ServerBuilder {
+	b.v.Memory = v
+	return b
+}
+
+func (b *ServerBuilder) SetDisks(v ...string) *ServerBuilder {
+	b.v.Disks = slices.Clone(v)
+	return b
+}
+
+func (b *ServerBuilder) AddDisks(v ...string) *ServerBuilder {
+	b.v.Disks = append(b.v.Disks, v...)
+	return b
+}
+
+func (b *ServerBuilder) SetLabels(v map[string]string) *ServerBuilder {
+	b.v.Labels = maps.Clone(v)
+	return b
+}
+
+func (b *ServerBuilder) SetLabelsEntry(k string, v string) *ServerBuilder {
+	if b.v.Labels == nil {
+		b.v.Labels = map[string]string{}
+	}
+	b.v.Labels[k] = v
+	return b
+}
+
+func (b *ServerBuilder) SetNetwork(v Network) *ServerBuilder {
+	b.v.Network = v
+	return b
+}
+
+// EditNetwork 就地修改嵌套结构体
+func (b *ServerBuilder) EditNetwork(fn func(*Network)) *ServerBuilder {
+	fn(&b.v.Network)
+	return b
+}
+
+func (b *ServerBuilder) SetReplicas(v int) *ServerBuilder {
+	b.v.Replicas = &v
+	return b
+}
+
+func (b *ServerBuilder) SetTimeout(v time.Duration) *ServerBuilder {
+	b.v.Timeout = v
+	return b
+}
+
+// AddValidator 追加校验钩子，Build 时在 Server.Validate 之后执行
+func (b *ServerBuilder) AddValidator(fn func(Server) error) *ServerBuilder {
+	b.validators = append(b.validators, fn)
+	return b
+}
+
+// Build 执行 Validate 方法（如果有）和所有校验钩子，任一失败时返回合并后的错误
+func (b *ServerBuilder) Build() (Server, error) {
+	v := b.v
+	v.Disks = slices.Clone(b.v.Disks)
+	v.Labels = maps.Clone(b.v.Labels)
+	if b.v.Replicas != nil {
+		v.Replicas = new(int)
+		*v.Replicas = *b.v.Replicas
+	}
+	var errs []error
+	if h, ok := any(&v).(interface{ Validate() error }); ok {
+		errs = append(errs, h.Validate())
+	}
+	for _, fn := range b.validators {
+		errs = append(errs, fn(v))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Server{}, err
+	}
+	return v, nil
+}
@@ -0,0 +1,87 @@
+// Code generated by buildergen -type Server -steps Name; DO NOT EDIT.
+
+package builder
+
+import (
+	"fmt"
+	"time"
+)
+
+// ServerNameStep 第 1 步，必须设置 Name
+type ServerNameStep interface {
+	SetName(v string) ServerFinalStep
+}
+
+// ServerFinalStep 必填步骤已完成，可以设置可选字段并构建
+type ServerFinalStep interface {
+	SetCores(v int) ServerFinalStep
+	SetMemory(v int) ServerFinalStep
+	SetDisks(v ...string) ServerFinalStep
+	SetLabels(v map[string]string) ServerFinalStep
+	SetNetwork(v Network) ServerFinalStep
+	SetReplicas(v int) ServerFinalStep
+	SetTimeout(v time.Duration) ServerFinalStep
+	Build() (Server, error)
+}
+
+// NewServerStepBuilder 按 Name 的顺序设置必填字段后才能调用 Build，默认值和校验与 ServerBuilder 相同，
+// 必填字段设置为零值时 Build 返回错误
+func NewServerStepBuilder() ServerNameStep {
+	return serverStepBuilder{b: NewServerBuilder()}
+}
+
+// serverStepBuilder 每次调用都返回修改过的副本，不改变调用它的阶段值
+type serverStepBuilder struct {
+	b    *ServerBuilder
+	zero [1]bool // 各必填字段是否被设置为零值
+}
+
+func (s serverStepBuilder) SetName(v string) ServerFinalStep {
+	s.zero[0] = v == ""
+	s.b = s.b.Clone().SetName(v)
+	return s
+}
+
+func (s serverStepBuilder) SetCores(v int) ServerFinalStep {
+	s.b = s.b.Clone().SetCores(v)
+	return s
+}
+
+func (s serverStepBuilder) SetMemory(v int) ServerFinalStep {
+	s.b = s.b.Clone().SetMemory(v)
+	return s
+}
+
+func (s serverStepBuilder) SetDisks(v ...string) ServerFinalStep {
+	s.b = s.b.Clone().SetDisks(v...)
+	return s
+}
+
+func (s serverStepBuilder) SetLabels(v map[string]string) ServerFinalStep {
+	s.b = s.b.Clone().SetLabels(v)
+	return s
+}
+
+func (s serverStepBuilder) SetNetwork(v Network) ServerFinalStep {
+	s.b = s.b.Clone().SetNetwork(v)
+	return s
+}
+
+func (s serverStepBuilder) SetReplicas(v int) ServerFinalStep {
+	s.b = s.b.Clone().SetReplicas(v)
+	return s
+}
+
+func (s serverStepBuilder) SetTimeout(v time.Duration) ServerFinalStep {
+	s.b = s.b.Clone().SetTimeout(v)
+	return s
+}
+
+func (s serverStepBuilder) Build() (Server, error) {
+	for i, step := range [...]string{"Name"} {
+		if s.zero[i] {
+			return Server{}, fmt.Errorf("server: required step %s must not be the zero value", step)
+		}
+	}
+	return s.b.Build()
+}
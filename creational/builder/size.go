@@ -0,0 +1,110 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Size 容量，单位 GB，按 1024 进位
+// 文本形式为 "32GB"、"1TB"，不带单位时按 GB 处理
+type Size int
+
+const (
+	GB Size = 1
+	TB      = 1024 * GB
+)
+
+// sizeUnits 支持的单位及对应的 GB 数，MB 只接受整 GB 的值
+var sizeUnits = map[string]float64{
+	"":    1,
+	"MB":  1.0 / 1024,
+	"MIB": 1.0 / 1024,
+	"M":   1.0 / 1024,
+	"GB":  1,
+	"GIB": 1,
+	"G":   1,
+	"TB":  1024,
+	"TIB": 1024,
+	"T":   1024,
+}
+
+// ParseSize 解析 "32GB"、"1TB"、"1.5 TB"、"512" 等形式，单位不区分大小写
+// 负数和超出 Size 范围的值返回错误
+func ParseSize(s string) (Size, error) {
+	t := strings.TrimSpace(s)
+	i := strings.IndexFunc(t, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+' })
+	if i < 0 {
+		i = len(t)
+	}
+	num, unit := t[:i], strings.ToUpper(strings.TrimSpace(t[i:]))
+	if num == "" {
+		return 0, fmt.Errorf("size %q: invalid number", s)
+	}
+	factor, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("size %q: unknown unit %q", s, t[i:])
+	}
+	if n, err := strconv.Atoi(num); err == nil && factor >= 1 {
+		switch {
+		case n < 0:
+			return 0, fmt.Errorf("size %q: must not be negative", s)
+		case n > math.MaxInt/int(factor):
+			return 0, fmt.Errorf("size %q: too large", s)
+		}
+		return Size(n * int(factor)), nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("size %q: invalid number", s)
+	}
+	gb := f * factor
+	switch {
+	case gb < 0:
+		return 0, fmt.Errorf("size %q: must not be negative", s)
+	// float64(math.MaxInt) 等于 2^63，不小于它的值转换成 int 会溢出
+	case gb >= math.MaxInt:
+		return 0, fmt.Errorf("size %q: too large", s)
+	case gb != math.Trunc(gb):
+		return 0, fmt.Errorf("size %q: not a whole number of GB", s)
+	}
+	return Size(gb), nil
+}
+
+// String 能整除 TB 时以 TB 表示，否则以 GB 表示
+func (s Size) String() string {
+	if s != 0 && s%TB == 0 {
+		return strconv.Itoa(int(s/TB)) + "TB"
+	}
+	return strconv.Itoa(int(s)) + "GB"
+}
+
+func (s Size) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *Size) UnmarshalText(b []byte) error {
+	v, err := ParseSize(string(b))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// MarshalJSON 输出带单位的字符串
+func (s Size) MarshalJSON() ([]byte, error) { return json.Marshal(s.String()) }
+
+// UnmarshalJSON 接受带单位的字符串，也接受按 GB 计的数字
+func (s *Size) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err != nil {
+		var n json.Number
+		if json.Unmarshal(b, &n) != nil {
+			return fmt.Errorf("size: want a string like \"32GB\" or a number of GB, got %s", b)
+		}
+		text = n.String()
+	}
+	return s.UnmarshalText([]byte(text))
+}
@@ -0,0 +1,112 @@
+package builder
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Size
+	}{
+		{"32GB", 32 * GB},
+		{"32", 32 * GB},
+		{" 16 gb ", 16 * GB},
+		{"1TB", TB},
+		{"1.5TB", 1536 * GB},
+		{"2 TiB", 2 * TB},
+		{"2048MB", 2 * GB},
+		{"64G", 64 * GB},
+		{"+8GB", 8 * GB},
+		{"-0", 0},
+		{"8589934592TB", 8589934592 * TB},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for in, msg := range map[string]string{
+		"":                             `size "": invalid number`,
+		"GB":                           `size "GB": invalid number`,
+		"32PB":                         `size "32PB": unknown unit "PB"`,
+		"512MB":                        `size "512MB": not a whole number of GB`,
+		"1.2.3":                        `size "1.2.3": invalid number`,
+		"-8GB":                         `size "-8GB": must not be negative`,
+		"-1.5TB":                       `size "-1.5TB": must not be negative`,
+		"9007199254740992TB":           `size "9007199254740992TB": too large`,
+		"9223372036854775808":          `size "9223372036854775808": too large`,
+		"99999999999999999999T":        `size "99999999999999999999T": too large`,
+		"1" + strings.Repeat("0", 400): `size "1` + strings.Repeat("0", 400) + `": too large`,
+	} {
+		_, err := ParseSize(in)
+		assert.EqualError(t, err, msg, in)
+	}
+}
+
+func TestSizeString(t *testing.T) {
+	assert.Equal(t, "0GB", Size(0).String())
+	assert.Equal(t, "512GB", (512 * GB).String())
+	assert.Equal(t, "1536GB", (1536 * GB).String())
+	assert.Equal(t, "2TB", (2 * TB).String())
+	assert.Equal(t, "-8GB", (-8 * GB).String())
+}
+
+func TestComputerRoundTrip(t *testing.T) {
+	pc := Computer{CPU: "AMD 7950X", Memory: 64 * GB, Disk: 2 * TB, GPU: "RTX 4090", PSU: 1000}
+
+	b, err := json.Marshal(pc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cpu":"AMD 7950X","memory":"64GB","disk":"2TB","gpu":"RTX 4090","psu":1000}`, string(b))
+	var fromJSON Computer
+	require.NoError(t, json.Unmarshal(b, &fromJSON))
+	assert.Equal(t, pc, fromJSON)
+
+	y, err := yaml.Marshal(pc)
+	require.NoError(t, err)
+	assert.Equal(t, "cpu: AMD 7950X\nmemory: 64GB\ndisk: 2TB\ngpu: RTX 4090\npsu: 1000\n", string(y))
+	var fromYAML Computer
+	require.NoError(t, yaml.Unmarshal(y, &fromYAML))
+	assert.Equal(t, pc, fromYAML)
+
+	// 数字按 GB 处理
+	var c Computer
+	require.NoError(t, json.Unmarshal([]byte(`{"memory":32,"disk":"1.5 TB"}`), &c))
+	assert.Equal(t, Computer{Memory: 32 * GB, Disk: 1536 * GB}, c)
+	require.NoError(t, yaml.Unmarshal([]byte("memory: 16\ndisk: 512gb\n"), &c))
+	assert.Equal(t, Computer{Memory: 16 * GB, Disk: 512 * GB}, c)
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"memory":true}`), &c), `size: want a string like "32GB" or a number of GB, got true`)
+	assert.ErrorContains(t, yaml.Unmarshal([]byte("memory: lots\n"), &c), `size "lots": invalid number`)
+}
+
+func TestBuilderUnitSetters(t *testing.T) {
+	pc, err := NewComputerBuilder().SetCPU("Intel i9").SetMemoryString("64GB").SetDiskString("1TB").Build()
+	require.NoError(t, err)
+	assert.Equal(t, Computer{CPU: "Intel i9", Memory: 64 * GB, Disk: TB, GPU: "RTX 3080", PSU: 750}, pc)
+
+	b := NewComputerBuilder().SetCPU("Intel i9").SetMemoryString("lots").SetDiskString("4PB")
+	_, err = b.Build()
+	assert.EqualError(t, err, `invalid computer: memory: size "lots": invalid number; disk: size "4PB": unknown unit "PB"`)
+
+	// 再次设置合法值后清除之前的解析错误
+	_, err = b.Clone().SetMemoryString("32GB").SetDiskString("2TB").Build()
+	assert.NoError(t, err)
+
+	// SetMemory、WithMemory 和预设同样会清除
+	_, err = b.Clone().SetMemory(32).SetDisk(TB).Build()
+	assert.NoError(t, err)
+	c := b.Clone()
+	require.NoError(t, c.Apply(WithMemory(32), WithDisk(TB)))
+	_, err = c.Build()
+	assert.NoError(t, err)
+	_, err = Preset{Memory: 32, Disk: TB}.Apply(b.Clone()).Build()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNewComputerStepBuilder() {
+	// 必须先 SetCPU 再 SetMemory，之后才能设置可选字段和 Build
+	pc, err := NewComputerStepBuilder().
+		SetCPU("AMD 7950X").
+		SetMemory(64).
+		SetGPU("RTX 4090").
+		SetPSU(1000).
+		Build()
+	fmt.Printf("%+v %v\n", pc, err)
+	// Output: {CPU:AMD 7950X Memory:64GB Disk:512GB GPU:RTX 4090 PSU:1000} <nil>
+}
+
+// 必填阶段的接口上没有 Build，漏掉必填步骤的调用无法通过编译
+func TestStepInterfaces(t *testing.T) {
+	for _, stage := range []reflect.Type{
+		reflect.TypeFor[ComputerCPUStep](),
+		reflect.TypeFor[ComputerMemoryStep](),
+		reflect.TypeFor[ServerNameStep](),
+	} {
+		assert.Equal(t, 1, stage.NumMethod(), stage.Name())
+		_, ok := stage.MethodByName("Build")
+		assert.False(t, ok, stage.Name())
+	}
+	_, ok := reflect.TypeFor[ComputerFinalStep]().MethodByName("Build")
+	assert.True(t, ok)
+}
+
+func TestStepBuilderSharesValidation(t *testing.T) {
+	_, err := NewComputerStepBuilder().SetCPU("AMD 7950X").SetMemory(-1).SetGPU("RTX 4090").SetPSU(500).Build()
+	assert.EqualError(t, err, "invalid computer: memory: must be between 1 and 2048, got -1; "+
+		"psu: 500W is not enough for RTX 4090, need at least 850W")
+
+	s, err := NewServerStepBuilder().SetName("db").SetDisks("nvme").Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nvme"}, s.Disks)
+	assert.Equal(t, 8, s.Cores)
+}
+
+// 必填步骤设置为零值时不能由 ComputerBuilder 的默认值顶替
+func TestStepBuilderRejectsZero(t *testing.T) {
+	_, err := NewComputerStepBuilder().SetCPU("AMD 7950X").SetMemory(0).Build()
+	assert.EqualError(t, err, "computer: required step Memory must not be the zero value")
+
+	_, err = NewComputerStepBuilder().SetCPU("").SetMemory(0).Build()
+	assert.EqualError(t, err, "computer: required step CPU must not be the zero value")
+
+	_, err = NewServerStepBuilder().SetName("").Build()
+	assert.EqualError(t, err, "server: required step Name must not be the zero value")
+}
+
+// 每一步都返回新的阶段值，保存下来的阶段可以分别继续构建
+func TestStepBuilderStagesIndependent(t *testing.T) {
+	s := NewComputerStepBuilder()
+	a := s.SetCPU("A").SetMemory(8)
+	b := s.SetCPU("B").SetMemory(16)
+	withGPU := a.SetGPU("RTX 4090").SetPSU(1000)
+
+	pa, err := a.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "A", pa.CPU)
+	assert.Equal(t, Size(8), pa.Memory)
+	assert.NotEqual(t, "RTX 4090", pa.GPU)
+
+	pb, err := b.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "B", pb.CPU)
+	assert.Equal(t, Size(16), pb.Memory)
+
+	pg, err := withGPU.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "RTX 4090", pg.GPU)
+}
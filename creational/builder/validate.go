@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Violation 一条违反的规则
+type Violation struct {
+	Field   string // 字段路径，如 "memory"
+	Message string
+}
+
+func (v Violation) String() string { return v.Field + ": " + v.Message }
+
+// ValidationError 校验失败，列出全部违反的规则
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return "invalid computer: " + strings.Join(parts, "; ")
+}
+
+// Rule 声明式校验规则，Check 返回空字符串表示通过
+type Rule struct {
+	Field string
+	Check func(c Computer) string
+}
+
+// Required 字段不能为空
+func Required(field string, get func(c Computer) string) Rule {
+	return Rule{Field: field, Check: func(c Computer) string {
+		if strings.TrimSpace(get(c)) == "" {
+			return "is required"
+		}
+		return ""
+	}}
+}
+
+// IntRange 整数字段必须在 [min, max] 范围内
+func IntRange(field string, min, max int, get func(c Computer) int) Rule {
+	return Rule{Field: field, Check: func(c Computer) string {
+		if v := get(c); v < min || v > max {
+			return fmt.Sprintf("must be between %d and %d, got %d", min, max, v)
+		}
+		return ""
+	}}
+}
+
+// GPUMinPSU 各显卡要求的最低电源功率（W）
+var GPUMinPSU = map[string]int{
+	"集成显卡":        300,
+	"RTX 3060":    550,
+	"RTX 3080":    750,
+	"RTX 4070":    650,
+	"RTX 4080":    750,
+	"RTX 4090":    850,
+	"RX 7900 XTX": 800,
+}
+
+// FieldRules 必填字段和取值范围
+var FieldRules = []Rule{
+	Required("cpu", func(c Computer) string { return c.CPU }),
+	IntRange("memory", 1, 2048, func(c Computer) int { return int(c.Memory) }),
+	IntRange("disk", 32, 65536, func(c Computer) int { return int(c.Disk) }),
+	IntRange("psu", 200, 2000, func(c Computer) int { return c.PSU }),
+}
+
+// GPURules 按 GPUMinPSU 检查显卡型号以及显卡与电源的匹配；使用配件目录时由目录的功耗检查代替
+var GPURules = []Rule{
+	{Field: "gpu", Check: func(c Computer) string {
+		if _, ok := GPUMinPSU[c.GPU]; !ok {
+			return fmt.Sprintf("unknown gpu %q", c.GPU)
+		}
+		return ""
+	}},
+	{Field: "psu", Check: func(c Computer) string {
+		if need, ok := GPUMinPSU[c.GPU]; ok && c.PSU < need {
+			return fmt.Sprintf("%dW is not enough for %s, need at least %dW", c.PSU, c.GPU, need)
+		}
+		return ""
+	}},
+}
+
+// DefaultRules 内置规则：FieldRules 加 GPURules
+var DefaultRules = slices.Concat(FieldRules, GPURules)
+
+// Validate 按 DefaultRules 和额外规则校验，返回 nil 或 *ValidationError
+func Validate(c Computer, extra ...Rule) error {
+	return validationError(check(c, DefaultRules, extra))
+}
+
+// check 依次执行各组规则，收集所有违反的规则
+func check(c Computer, groups ...[]Rule) []Violation {
+	var violations []Violation
+	for _, rules := range groups {
+		for _, r := range rules {
+			if msg := r.Check(c); msg != "" {
+				violations = append(violations, Violation{Field: r.Field, Message: msg})
+			}
+		}
+	}
+	return violations
+}
+
+func validationError(violations []Violation) error {
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
@@ -1,33 +1,165 @@
-package main
+package abstract_factory
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
 
-// Button 两类产品接口
-type Button interface{ Click() }
-type TextBox interface{ Show() }
+	"github.com/qiye45/go_design_pattern/creational/factory/parser"
+)
 
-// UIFactory 抽象工厂
+// Widget 所有控件都渲染到 io.Writer
+type Widget interface{ Render(w io.Writer) error }
+
+// 各类产品接口，除了渲染还各自提供创建时传入的内容
+type (
+	Button interface {
+		Widget
+		Caption() string
+	}
+	TextBox interface {
+		Widget
+		Field() (name, value string)
+	}
+	Checkbox interface {
+		Widget
+		IsChecked() bool
+	}
+	Label interface {
+		Widget
+		Content() string
+	}
+	Dialog interface {
+		Widget
+		Heading() string
+	}
+)
+
+// Layout 容器控件，按方向排列子控件
+type Layout interface {
+	Widget
+	Add(children ...Widget) Layout
+}
+
+// Direction 布局方向
+type Direction int
+
+const (
+	Horizontal Direction = iota
+	Vertical
+)
+
+// UIFactory 抽象工厂，同一个工厂创建的控件属于同一个系列
 type UIFactory interface {
-	CreateButton() Button
-	CreateTextBox() TextBox
+	CreateButton(label string) Button
+	CreateTextBox(name, value string) TextBox
+	CreateCheckbox(label string, checked bool) Checkbox
+	CreateLabel(text string) Label
+	CreateDialog(title string, body Widget) Dialog
+	CreateLayout(dir Direction) Layout
 }
 
-// WinButton win系列
-type WinButton struct{}
+// families 已注册的控件系列，值为按主题创建工厂的构造函数
+var (
+	familiesMu sync.RWMutex
+	families   = map[string]func(Theme) UIFactory{}
+)
 
-func (WinButton) Click() { fmt.Println("Win按钮") }
+// Register 注册控件系列，同名注册会覆盖；可以并发调用
+func Register(name string, newFactory func(Theme) UIFactory) {
+	familiesMu.Lock()
+	defer familiesMu.Unlock()
+	families[name] = newFactory
+}
 
-type WinTextBox struct{}
+// Families 已注册的系列名称
+func Families() []string {
+	familiesMu.RLock()
+	defer familiesMu.RUnlock()
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-func (WinTextBox) Show() { fmt.Println("Win文本框") }
+func init() {
+	Register("win", func(t Theme) UIFactory { return WinFactory{Theme: t} })
+	Register("html", func(t Theme) UIFactory { return HTMLFactory{Theme: t} })
+	Register("ansi", func(t Theme) UIFactory { return ANSIFactory{Theme: t} })
+}
 
-type WinFactory struct{}
+// Config 界面配置
+type Config struct {
+	Family string `json:"family" yaml:"family" toml:"family" ini:"family"`
+	Theme  string `json:"theme" yaml:"theme" toml:"theme" ini:"theme"` // 主题文件路径，为空时使用 DefaultTheme
+}
+
+// LoadConfig 读取界面配置文件，格式按文件后缀或内容识别
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	err = (&parser.AutoParser{Filename: path}).Unmarshal(string(data), &cfg)
+	return cfg, err
+}
+
+// NewFamily 用指定主题创建已注册的系列，主题没有通过 Validate 时返回错误
+// 各系列直接把令牌写入样式和转义序列，不再检查
+func NewFamily(name string, theme Theme) (UIFactory, error) {
+	familiesMu.RLock()
+	newFactory, ok := families[name]
+	familiesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown ui family %q, available: %v", name, Families())
+	}
+	if err := theme.Validate(); err != nil {
+		return nil, fmt.Errorf("ui family %s: invalid theme: %w", name, err)
+	}
+	return newFactory(theme), nil
+}
+
+// NewUIFactory 根据配置选择控件系列并加载主题
+func NewUIFactory(cfg Config) (UIFactory, error) {
+	theme := DefaultTheme()
+	if cfg.Theme != "" {
+		var err error
+		if theme, err = LoadTheme(cfg.Theme); err != nil {
+			return nil, err
+		}
+	}
+	return NewFamily(cfg.Family, theme)
+}
 
-func (WinFactory) CreateButton() Button   { return WinButton{} }
-func (WinFactory) CreateTextBox() TextBox { return WinTextBox{} }
+// renderWidget 渲染单个控件，nil 渲染为空
+func renderWidget(w io.Writer, c Widget) error {
+	if c == nil {
+		return nil
+	}
+	return c.Render(w)
+}
 
-func main() {
-	var f UIFactory = WinFactory{}
-	f.CreateButton().Click()
-	f.CreateTextBox().Show()
+// renderAll 依次渲染多个控件，sep 为相邻控件之间的分隔，nil 子控件被跳过
+func renderAll(w io.Writer, children []Widget, sep string) error {
+	first := true
+	for _, c := range children {
+		if c == nil {
+			continue
+		}
+		if !first {
+			if _, err := io.WriteString(w, sep); err != nil {
+				return err
+			}
+		}
+		first = false
+		if err := c.Render(w); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,177 @@
+package abstract_factory
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ANSI 转义序列
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiUnderline = "\x1b[4m"
+)
+
+// ansiTextBoxWidth 输入框的最小宽度（字符数）
+const ansiTextBoxWidth = 20
+
+func fg(c string) string {
+	r, g, b := hexRGB(c)
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+}
+
+func bg(c string) string {
+	r, g, b := hexRGB(c)
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", r, g, b)
+}
+
+// weight 字重 >= 600 时显示为粗体
+func (t Theme) weight() string {
+	if t.Typography.FontWeight >= 600 {
+		return ansiBold
+	}
+	return ""
+}
+
+// ANSIButton ansi系列，渲染为 24 位真彩色的终端文本
+type ANSIButton struct {
+	Label string
+	Theme Theme
+}
+
+func (b ANSIButton) Caption() string { return b.Label }
+
+func (b ANSIButton) Render(w io.Writer) error {
+	t := b.Theme
+	pad := strings.Repeat(" ", t.Spacing.Padding)
+	_, err := fmt.Fprintf(w, "%s%s%s[%s%s%s]%s", ansiBold, bg(t.Colors.Primary), fg(t.Colors.OnPrimary),
+		pad, stripControl(b.Label), pad, ansiReset)
+	return err
+}
+
+// ANSITextBox 输入框至少 ansiTextBoxWidth 个字符宽，不足时补空格
+type ANSITextBox struct {
+	Name, Value string
+	Theme       Theme
+}
+
+func (b ANSITextBox) Field() (name, value string) { return b.Name, b.Value }
+
+func (b ANSITextBox) Render(w io.Writer) error {
+	t := b.Theme
+	value := stripControl(b.Value)
+	pad := max(ansiTextBoxWidth-utf8.RuneCountInString(value), 0)
+	_, err := fmt.Fprintf(w, "%s%s%s: %s%s%s%s%s", t.weight(), fg(t.Colors.Text), stripControl(b.Name),
+		fg(t.Colors.Border), ansiUnderline, value, strings.Repeat(" ", pad), ansiReset)
+	return err
+}
+
+type ANSICheckbox struct {
+	Label   string
+	Checked bool
+	Theme   Theme
+}
+
+func (c ANSICheckbox) IsChecked() bool { return c.Checked }
+
+func (c ANSICheckbox) Render(w io.Writer) error {
+	mark := " "
+	if c.Checked {
+		mark = "x"
+	}
+	_, err := fmt.Fprintf(w, "%s%s[%s%s%s] %s%s", c.Theme.weight(), fg(c.Theme.Colors.Text),
+		fg(c.Theme.Colors.Primary), mark, fg(c.Theme.Colors.Text), stripControl(c.Label), ansiReset)
+	return err
+}
+
+type ANSILabel struct {
+	Text  string
+	Theme Theme
+}
+
+func (l ANSILabel) Content() string { return l.Text }
+
+func (l ANSILabel) Render(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s%s%s%s", l.Theme.weight(), fg(l.Theme.Colors.Text), stripControl(l.Text), ansiReset)
+	return err
+}
+
+// ANSIDialog 用框线字符画出左边框，正文每行缩进 Padding 个字符
+type ANSIDialog struct {
+	Title string
+	Body  Widget
+	Theme Theme
+}
+
+func (d ANSIDialog) Heading() string { return d.Title }
+
+func (d ANSIDialog) Render(w io.Writer) error {
+	t := d.Theme
+	var body bytes.Buffer
+	if err := renderWidget(&body, d.Body); err != nil {
+		return err
+	}
+	border := fg(t.Colors.Border)
+	pad := strings.Repeat(" ", t.Spacing.Padding)
+	if _, err := fmt.Fprintf(w, "%s┌─ %s%s%s%s\n", border, ansiBold, fg(t.Colors.Text), stripControl(d.Title), ansiReset); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(body.String(), "\n") {
+		if _, err := fmt.Fprintf(w, "%s│%s%s%s\n", border, ansiReset, pad, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s└─%s", border, ansiReset)
+	return err
+}
+
+// ANSILayout 水平方向用 Gap 个空格分隔，垂直方向每个子控件占一行
+type ANSILayout struct {
+	Dir      Direction
+	Children []Widget
+	Theme    Theme
+}
+
+func (l *ANSILayout) Add(children ...Widget) Layout {
+	l.Children = append(l.Children, children...)
+	return l
+}
+
+func (l *ANSILayout) Render(w io.Writer) error {
+	sep := strings.Repeat(" ", l.Theme.Spacing.Gap)
+	if l.Dir == Vertical {
+		sep = "\n"
+	}
+	return renderAll(w, l.Children, sep)
+}
+
+type ANSIFactory struct{ Theme Theme }
+
+func (f ANSIFactory) CreateButton(label string) Button {
+	return ANSIButton{Label: label, Theme: f.Theme}
+}
+func (f ANSIFactory) CreateTextBox(name, value string) TextBox {
+	return ANSITextBox{Name: name, Value: value, Theme: f.Theme}
+}
+func (f ANSIFactory) CreateCheckbox(label string, checked bool) Checkbox {
+	return ANSICheckbox{Label: label, Checked: checked, Theme: f.Theme}
+}
+func (f ANSIFactory) CreateLabel(text string) Label { return ANSILabel{Text: text, Theme: f.Theme} }
+func (f ANSIFactory) CreateDialog(title string, body Widget) Dialog {
+	return ANSIDialog{Title: title, Body: body, Theme: f.Theme}
+}
+func (f ANSIFactory) CreateLayout(dir Direction) Layout { return &ANSILayout{Dir: dir, Theme: f.Theme} }
+
+// stripControl 去掉控制字符，避免内容里的转义序列破坏终端样式
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
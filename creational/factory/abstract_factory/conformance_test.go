@@ -0,0 +1,18 @@
+package abstract_factory_test
+
+import (
+	"testing"
+
+	af "github.com/qiye45/go_design_pattern/creational/factory/abstract_factory"
+	"github.com/qiye45/go_design_pattern/creational/factory/abstract_factory/uitest"
+)
+
+func TestConformance(t *testing.T) {
+	uitest.Conformance(t, af.DefaultTheme())
+
+	dark := af.DefaultTheme()
+	dark.Colors.Background = "#141414"
+	dark.Colors.Text = "#f0f0f0"
+	dark.Typography.FontWeight = 700
+	uitest.Conformance(t, dark)
+}
@@ -0,0 +1,144 @@
+package abstract_factory
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "重新生成 testdata 下的 golden 文件")
+
+func Example() {
+	var f UIFactory = WinFactory{}
+	var buf bytes.Buffer
+	_ = f.CreateButton("确定").Render(&buf)
+	buf.WriteString(" ")
+	_ = f.CreateTextBox("user", "张三").Render(&buf)
+	fmt.Println(buf.String())
+	// Output: Win按钮[确定] Win文本框[user=张三]
+}
+
+// renderForm 用同一个工厂渲染一个登录对话框
+func renderForm(f UIFactory) ([]byte, error) {
+	form := f.CreateLayout(Vertical).Add(
+		f.CreateLayout(Horizontal).Add(f.CreateLabel("用户"), f.CreateTextBox("user", "张三")),
+		f.CreateTextBox("note", `<a href="x">&</a>`),
+		f.CreateCheckbox("记住我", true),
+		f.CreateLayout(Horizontal).Add(f.CreateButton("登录"), f.CreateButton("\x1b[31m红色")),
+	)
+	var buf bytes.Buffer
+	if err := f.CreateDialog("登录", form).Render(&buf); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func TestFamiliesGolden(t *testing.T) {
+	for _, name := range Families() {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewUIFactory(Config{Family: name})
+			require.NoError(t, err)
+			got, err := renderForm(f)
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0o644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	for file, content := range map[string]string{
+		"ui.yaml": "family: html\n",
+		"ui.json": `{"family": "html"}`,
+		"ui.ini":  "family = html\n",
+	} {
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err, file)
+
+		f, err := NewUIFactory(cfg)
+		require.NoError(t, err, file)
+		assert.IsType(t, HTMLFactory{}, f)
+	}
+
+	_, err := NewUIFactory(Config{Family: "qt"})
+	assert.EqualError(t, err, `unknown ui family "qt", available: [ansi html win]`)
+}
+
+func TestNewFamilyInvalidTheme(t *testing.T) {
+	theme := DefaultTheme()
+	theme.Colors.Primary = `red;background:url(x)"`
+	for _, name := range Families() {
+		_, err := NewFamily(name, theme)
+		assert.EqualError(t, err, "ui family "+name+`: invalid theme: colors.primary: invalid color "red;background:url(x)\"", want #RRGGBB`)
+	}
+}
+
+func TestRegisterConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Register(fmt.Sprintf("test%d", i), func(t Theme) UIFactory { return WinFactory{Theme: t} })
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = NewFamily("win", DefaultTheme())
+		}()
+	}
+	wg.Wait()
+	t.Cleanup(func() {
+		familiesMu.Lock()
+		defer familiesMu.Unlock()
+		for i := range 4 {
+			delete(families, fmt.Sprintf("test%d", i))
+		}
+	})
+	assert.Subset(t, Families(), []string{"test0", "test3", "win"})
+}
+
+func TestLoadTheme(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dark.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[colors]\nbackground = \"#141414\"\ntext = \"#f0f0f0\"\n\n[typography]\nfont_weight = 700\n"), 0o644))
+
+	theme, err := LoadTheme(path)
+	require.NoError(t, err)
+	assert.Equal(t, "#141414", theme.Colors.Background)
+	assert.Equal(t, 700, theme.Typography.FontWeight)
+	// 没写的令牌沿用默认值
+	assert.Equal(t, DefaultTheme().Colors.Primary, theme.Colors.Primary)
+	assert.Equal(t, DefaultTheme().Spacing, theme.Spacing)
+
+	cfg := filepath.Join(dir, "ui.ini")
+	require.NoError(t, os.WriteFile(cfg, []byte("family = html\ntheme = "+path+"\n"), 0o644))
+	c, err := LoadConfig(cfg)
+	require.NoError(t, err)
+	f, err := NewUIFactory(c)
+	require.NoError(t, err)
+	assert.Equal(t, theme, f.(HTMLFactory).Theme)
+
+	bad := filepath.Join(dir, "bad.yaml")
+	require.NoError(t, os.WriteFile(bad, []byte("colors:\n  primary: blue\nspacing:\n  gap: -1\ntypography:\n  font_size: 0\n"), 0o644))
+	_, err = LoadTheme(bad)
+	assert.EqualError(t, err, "theme "+bad+`: colors.primary: invalid color "blue", want #RRGGBB
+spacing.gap: must not be negative, got -1
+typography.font_size: must be positive, got 0`)
+}
@@ -0,0 +1,141 @@
+package abstract_factory
+
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
+// htmlUnit html 系列中一个间距单位对应的像素
+const htmlUnit = 4
+
+// font 主题字体对应的 CSS
+func (t Theme) font() string {
+	return fmt.Sprintf("font:%d %dpx %s", t.Typography.FontWeight, t.Typography.FontSize, t.Typography.FontFamily)
+}
+
+// HTMLButton html系列，渲染为带内联样式的 HTML 片段
+type HTMLButton struct {
+	Label string
+	Theme Theme
+}
+
+func (b HTMLButton) Caption() string { return b.Label }
+
+func (b HTMLButton) Render(w io.Writer) error {
+	t := b.Theme
+	_, err := fmt.Fprintf(w, `<button type="button" class="btn" style="background:%s;color:%s;padding:%dpx;%s">%s</button>`,
+		t.Colors.Primary, t.Colors.OnPrimary, t.Spacing.Padding*htmlUnit, html.EscapeString(t.font()), html.EscapeString(b.Label))
+	return err
+}
+
+type HTMLTextBox struct {
+	Name, Value string
+	Theme       Theme
+}
+
+func (b HTMLTextBox) Field() (name, value string) { return b.Name, b.Value }
+
+func (b HTMLTextBox) Render(w io.Writer) error {
+	t := b.Theme
+	_, err := fmt.Fprintf(w, `<input type="text" class="textbox" name="%s" value="%s" style="border:1px solid %s;color:%s;padding:%dpx;%s">`,
+		html.EscapeString(b.Name), html.EscapeString(b.Value),
+		t.Colors.Border, t.Colors.Text, t.Spacing.Padding*htmlUnit, html.EscapeString(t.font()))
+	return err
+}
+
+type HTMLCheckbox struct {
+	Label   string
+	Checked bool
+	Theme   Theme
+}
+
+func (c HTMLCheckbox) IsChecked() bool { return c.Checked }
+
+func (c HTMLCheckbox) Render(w io.Writer) error {
+	checked := ""
+	if c.Checked {
+		checked = " checked"
+	}
+	_, err := fmt.Fprintf(w, `<label class="checkbox" style="color:%s;%s"><input type="checkbox"%s> %s</label>`,
+		c.Theme.Colors.Text, html.EscapeString(c.Theme.font()), checked, html.EscapeString(c.Label))
+	return err
+}
+
+type HTMLLabel struct {
+	Text  string
+	Theme Theme
+}
+
+func (l HTMLLabel) Content() string { return l.Text }
+
+func (l HTMLLabel) Render(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `<span class="label" style="color:%s;%s">%s</span>`,
+		l.Theme.Colors.Text, html.EscapeString(l.Theme.font()), html.EscapeString(l.Text))
+	return err
+}
+
+type HTMLDialog struct {
+	Title string
+	Body  Widget
+	Theme Theme
+}
+
+func (d HTMLDialog) Heading() string { return d.Title }
+
+func (d HTMLDialog) Render(w io.Writer) error {
+	t := d.Theme
+	if _, err := fmt.Fprintf(w, `<div class="dialog" role="dialog" style="background:%s;border:1px solid %s;padding:%dpx"><h2 style="color:%s;%s">%s</h2>`,
+		t.Colors.Background, t.Colors.Border, t.Spacing.Padding*htmlUnit,
+		t.Colors.Text, html.EscapeString(t.font()), html.EscapeString(d.Title)); err != nil {
+		return err
+	}
+	if err := renderWidget(w, d.Body); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</div>")
+	return err
+}
+
+type HTMLLayout struct {
+	Dir      Direction
+	Children []Widget
+	Theme    Theme
+}
+
+func (l *HTMLLayout) Add(children ...Widget) Layout {
+	l.Children = append(l.Children, children...)
+	return l
+}
+
+func (l *HTMLLayout) Render(w io.Writer) error {
+	dir := "row"
+	if l.Dir == Vertical {
+		dir = "column"
+	}
+	if _, err := fmt.Fprintf(w, `<div class="layout" style="display:flex;flex-direction:%s;gap:%dpx">`, dir, l.Theme.Spacing.Gap*htmlUnit); err != nil {
+		return err
+	}
+	if err := renderAll(w, l.Children, ""); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</div>")
+	return err
+}
+
+type HTMLFactory struct{ Theme Theme }
+
+func (f HTMLFactory) CreateButton(label string) Button {
+	return HTMLButton{Label: label, Theme: f.Theme}
+}
+func (f HTMLFactory) CreateTextBox(name, value string) TextBox {
+	return HTMLTextBox{Name: name, Value: value, Theme: f.Theme}
+}
+func (f HTMLFactory) CreateCheckbox(label string, checked bool) Checkbox {
+	return HTMLCheckbox{Label: label, Checked: checked, Theme: f.Theme}
+}
+func (f HTMLFactory) CreateLabel(text string) Label { return HTMLLabel{Text: text, Theme: f.Theme} }
+func (f HTMLFactory) CreateDialog(title string, body Widget) Dialog {
+	return HTMLDialog{Title: title, Body: body, Theme: f.Theme}
+}
+func (f HTMLFactory) CreateLayout(dir Direction) Layout { return &HTMLLayout{Dir: dir, Theme: f.Theme} }
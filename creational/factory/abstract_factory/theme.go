@@ -0,0 +1,111 @@
+package abstract_factory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/qiye45/go_design_pattern/creational/factory/parser"
+)
+
+// Theme 主题，由颜色、间距、字体三组设计令牌组成
+type Theme struct {
+	Colors     ColorTokens      `json:"colors" yaml:"colors" toml:"colors" ini:"colors"`
+	Spacing    SpacingTokens    `json:"spacing" yaml:"spacing" toml:"spacing" ini:"spacing"`
+	Typography TypographyTokens `json:"typography" yaml:"typography" toml:"typography" ini:"typography"`
+}
+
+// ColorTokens 颜色，格式 #RRGGBB
+type ColorTokens struct {
+	Primary    string `json:"primary" yaml:"primary" toml:"primary" ini:"primary"`
+	OnPrimary  string `json:"on_primary" yaml:"on_primary" toml:"on_primary" ini:"on_primary"`
+	Text       string `json:"text" yaml:"text" toml:"text" ini:"text"`
+	Background string `json:"background" yaml:"background" toml:"background" ini:"background"`
+	Border     string `json:"border" yaml:"border" toml:"border" ini:"border"`
+}
+
+// SpacingTokens 间距，单位由系列决定：html 中 1 个单位为 4px，ansi 和 win 中为 1 个字符
+type SpacingTokens struct {
+	Gap     int `json:"gap" yaml:"gap" toml:"gap" ini:"gap"`
+	Padding int `json:"padding" yaml:"padding" toml:"padding" ini:"padding"`
+}
+
+// TypographyTokens 字体
+type TypographyTokens struct {
+	FontFamily string `json:"font_family" yaml:"font_family" toml:"font_family" ini:"font_family"`
+	FontSize   int    `json:"font_size" yaml:"font_size" toml:"font_size" ini:"font_size"`         // px
+	FontWeight int    `json:"font_weight" yaml:"font_weight" toml:"font_weight" ini:"font_weight"` // 100~900，ansi 中 >= 600 显示为粗体
+}
+
+// DefaultTheme 默认主题
+func DefaultTheme() Theme {
+	return Theme{
+		Colors: ColorTokens{
+			Primary:    "#1677ff",
+			OnPrimary:  "#ffffff",
+			Text:       "#1f1f1f",
+			Background: "#ffffff",
+			Border:     "#d9d9d9",
+		},
+		Spacing:    SpacingTokens{Gap: 2, Padding: 1},
+		Typography: TypographyTokens{FontFamily: "sans-serif", FontSize: 14, FontWeight: 400},
+	}
+}
+
+var colorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate 检查所有令牌，返回全部问题
+func (t Theme) Validate() error {
+	var errs []error
+	colors := []struct{ name, value string }{
+		{"colors.primary", t.Colors.Primary},
+		{"colors.on_primary", t.Colors.OnPrimary},
+		{"colors.text", t.Colors.Text},
+		{"colors.background", t.Colors.Background},
+		{"colors.border", t.Colors.Border},
+	}
+	for _, c := range colors {
+		if !colorRe.MatchString(c.value) {
+			errs = append(errs, fmt.Errorf("%s: invalid color %q, want #RRGGBB", c.name, c.value))
+		}
+	}
+	if t.Spacing.Gap < 0 {
+		errs = append(errs, fmt.Errorf("spacing.gap: must not be negative, got %d", t.Spacing.Gap))
+	}
+	if t.Spacing.Padding < 0 {
+		errs = append(errs, fmt.Errorf("spacing.padding: must not be negative, got %d", t.Spacing.Padding))
+	}
+	if t.Typography.FontFamily == "" {
+		errs = append(errs, errors.New("typography.font_family: required"))
+	}
+	if t.Typography.FontSize <= 0 {
+		errs = append(errs, fmt.Errorf("typography.font_size: must be positive, got %d", t.Typography.FontSize))
+	}
+	if w := t.Typography.FontWeight; w < 100 || w > 900 {
+		errs = append(errs, fmt.Errorf("typography.font_weight: must be in [100, 900], got %d", w))
+	}
+	return errors.Join(errs...)
+}
+
+// LoadTheme 读取主题文件，文件中没有写的令牌沿用 DefaultTheme
+func LoadTheme(path string) (Theme, error) {
+	theme := DefaultTheme()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return theme, err
+	}
+	if err := (&parser.AutoParser{Filename: path}).Unmarshal(string(data), &theme); err != nil {
+		return theme, err
+	}
+	if err := theme.Validate(); err != nil {
+		return theme, fmt.Errorf("theme %s: %w", path, err)
+	}
+	return theme, nil
+}
+
+// hexRGB 把 #RRGGBB 拆成三个分量，调用前应先 Validate（NewFamily 会检查）
+func hexRGB(c string) (r, g, b int) {
+	_, _ = fmt.Sscanf(c, "#%02x%02x%02x", &r, &g, &b)
+	return r, g, b
+}
@@ -0,0 +1,118 @@
+// Package uitest 检查控件系列是否完整、渲染是否确定，供各系列的测试调用
+package uitest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	af "github.com/qiye45/go_design_pattern/creational/factory/abstract_factory"
+)
+
+// Conformance 对所有已注册的系列执行 CheckFamily
+func Conformance(t *testing.T, theme af.Theme) {
+	t.Helper()
+	for _, name := range af.Families() {
+		t.Run(name, func(t *testing.T) {
+			CheckFamily(t, func() af.UIFactory {
+				f, err := af.NewFamily(name, theme)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return f
+			})
+		})
+	}
+}
+
+// CheckFamily 检查 newFactory 创建的工厂：
+//   - 每种控件都能创建，渲染不报错且输出非空，Caption、Field 等方法返回创建时传入的内容
+//   - 同一控件多次渲染、不同工厂实例渲染的结果完全一致
+//   - Dialog 和 Layout 的输出包含子控件的输出，Layout 按添加顺序排列
+//   - 没有正文的 Dialog 可以渲染，Layout 跳过 nil 子控件
+func CheckFamily(t *testing.T, newFactory func() af.UIFactory) {
+	t.Helper()
+	widgets := func(f af.UIFactory) map[string]af.Widget {
+		return map[string]af.Widget{
+			"Button":          f.CreateButton("确定"),
+			"TextBox":         f.CreateTextBox("user", "张三"),
+			"Checkbox":        f.CreateCheckbox("记住我", true),
+			"CheckboxOff":     f.CreateCheckbox("记住我", false),
+			"Label":           f.CreateLabel("用户名"),
+			"Dialog":          f.CreateDialog("登录", f.CreateLabel("正文")),
+			"LayoutH":         f.CreateLayout(af.Horizontal).Add(f.CreateLabel("左"), f.CreateLabel("右")),
+			"LayoutV":         f.CreateLayout(af.Vertical).Add(f.CreateLabel("上"), f.CreateLabel("下")),
+			"LayoutEmpty":     f.CreateLayout(af.Vertical),
+			"DialogWithPanel": f.CreateDialog("设置", f.CreateLayout(af.Vertical).Add(f.CreateCheckbox("a", false))),
+			"DialogNoBody":    f.CreateDialog("提示", nil),
+			"LayoutNilChild":  f.CreateLayout(af.Horizontal).Add(nil, f.CreateLabel("左"), nil, f.CreateLabel("右"), nil),
+		}
+	}
+
+	first, second := widgets(newFactory()), widgets(newFactory())
+	outputs := map[string]string{}
+	for name, w := range first {
+		if w == nil {
+			t.Errorf("%s: factory returned nil", name)
+			continue
+		}
+		out := render(t, name, w)
+		if out == "" && name != "LayoutEmpty" {
+			t.Errorf("%s: empty output", name)
+		}
+		if again := render(t, name, w); again != out {
+			t.Errorf("%s: output changed between renders:\n%q\n%q", name, out, again)
+		}
+		if other := second[name]; other == nil || render(t, name, other) != out {
+			t.Errorf("%s: output differs between factory instances", name)
+		}
+		outputs[name] = out
+	}
+
+	f := newFactory()
+	if got := f.CreateButton("确定").Caption(); got != "确定" {
+		t.Errorf("Button: Caption() = %q, want %q", got, "确定")
+	}
+	if name, value := f.CreateTextBox("user", "张三").Field(); name != "user" || value != "张三" {
+		t.Errorf("TextBox: Field() = %q, %q, want %q, %q", name, value, "user", "张三")
+	}
+	if !f.CreateCheckbox("a", true).IsChecked() || f.CreateCheckbox("a", false).IsChecked() {
+		t.Errorf("Checkbox: IsChecked() does not match the checked argument")
+	}
+	if got := f.CreateLabel("用户名").Content(); got != "用户名" {
+		t.Errorf("Label: Content() = %q, want %q", got, "用户名")
+	}
+	if got := f.CreateDialog("登录", f.CreateLabel("正文")).Heading(); got != "登录" {
+		t.Errorf("Dialog: Heading() = %q, want %q", got, "登录")
+	}
+	label := func(s string) string { return render(t, "Label", f.CreateLabel(s)) }
+	if !strings.Contains(outputs["Dialog"], label("正文")) {
+		t.Errorf("Dialog: output does not contain its body: %q", outputs["Dialog"])
+	}
+	for _, name := range []string{"LayoutH", "LayoutV"} {
+		out := outputs[name]
+		a, b := label("左"), label("右")
+		if name == "LayoutV" {
+			a, b = label("上"), label("下")
+		}
+		i, j := strings.Index(out, a), strings.Index(out, b)
+		if i < 0 || j < 0 || i > j {
+			t.Errorf("%s: children missing or out of order: %q", name, out)
+		}
+	}
+	if outputs["LayoutNilChild"] != outputs["LayoutH"] {
+		t.Errorf("Layout: nil children not skipped:\n%q\n%q", outputs["LayoutNilChild"], outputs["LayoutH"])
+	}
+	if outputs["CheckboxOff"] == outputs["Checkbox"] {
+		t.Errorf("Checkbox: checked and unchecked render the same: %q", outputs["Checkbox"])
+	}
+}
+
+func render(t *testing.T, name string, w af.Widget) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := w.Render(&buf); err != nil {
+		t.Errorf("%s: render: %v", name, err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,108 @@
+package abstract_factory
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WinButton win系列，纯文本输出，只使用主题中的间距：按钮内留 Padding 个空格
+type WinButton struct {
+	Label string
+	Theme Theme
+}
+
+func (b WinButton) Caption() string { return b.Label }
+
+func (b WinButton) Render(w io.Writer) error {
+	pad := strings.Repeat(" ", b.Theme.Spacing.Padding)
+	_, err := fmt.Fprintf(w, "Win按钮[%s%s%s]", pad, b.Label, pad)
+	return err
+}
+
+type WinTextBox struct{ Name, Value string }
+
+func (t WinTextBox) Field() (name, value string) { return t.Name, t.Value }
+
+func (t WinTextBox) Render(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Win文本框[%s=%s]", t.Name, t.Value)
+	return err
+}
+
+type WinCheckbox struct {
+	Label   string
+	Checked bool
+}
+
+func (c WinCheckbox) IsChecked() bool { return c.Checked }
+
+func (c WinCheckbox) Render(w io.Writer) error {
+	mark := " "
+	if c.Checked {
+		mark = "√"
+	}
+	_, err := fmt.Fprintf(w, "Win复选框[%s]%s", mark, c.Label)
+	return err
+}
+
+type WinLabel struct{ Text string }
+
+func (l WinLabel) Content() string { return l.Text }
+
+func (l WinLabel) Render(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Win标签[%s]", l.Text)
+	return err
+}
+
+type WinDialog struct {
+	Title string
+	Body  Widget
+}
+
+func (d WinDialog) Heading() string { return d.Title }
+
+func (d WinDialog) Render(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Win对话框[%s]{", d.Title); err != nil {
+		return err
+	}
+	if err := renderWidget(w, d.Body); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// WinLayout 水平方向用 Gap 个空格分隔，垂直方向每个子控件占一行
+type WinLayout struct {
+	Dir      Direction
+	Children []Widget
+	Theme    Theme
+}
+
+func (l *WinLayout) Add(children ...Widget) Layout {
+	l.Children = append(l.Children, children...)
+	return l
+}
+
+func (l *WinLayout) Render(w io.Writer) error {
+	sep := strings.Repeat(" ", l.Theme.Spacing.Gap)
+	if l.Dir == Vertical {
+		sep = "\n"
+	}
+	return renderAll(w, l.Children, sep)
+}
+
+type WinFactory struct{ Theme Theme }
+
+func (f WinFactory) CreateButton(label string) Button { return WinButton{Label: label, Theme: f.Theme} }
+func (WinFactory) CreateTextBox(name, value string) TextBox {
+	return WinTextBox{Name: name, Value: value}
+}
+func (WinFactory) CreateCheckbox(label string, checked bool) Checkbox {
+	return WinCheckbox{Label: label, Checked: checked}
+}
+func (WinFactory) CreateLabel(text string) Label { return WinLabel{Text: text} }
+func (WinFactory) CreateDialog(title string, body Widget) Dialog {
+	return WinDialog{Title: title, Body: body}
+}
+func (f WinFactory) CreateLayout(dir Direction) Layout { return &WinLayout{Dir: dir, Theme: f.Theme} }
@@ -0,0 +1,37 @@
+package factory_method
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleFactory() {
+	var f Factory = JsonFactory{}
+	p := f.Create()
+	v, err := p.Parse(`{"a":1}`)
+	fmt.Println(v, err)
+	// Output: map[a:1] <nil>
+}
+
+func TestFactories(t *testing.T) {
+	cases := []struct {
+		factory Factory
+		doc     string
+	}{
+		{JsonFactory{}, `{"server":{"port":80}}`},
+		{YamlFactory{}, "server:\n  port: 80\n"},
+		{TomlFactory{}, "[server]\nport = 80\n"},
+		{IniFactory{}, "[server]\nport = 80\n"},
+	}
+	for _, c := range cases {
+		v, err := c.factory.Create().Parse(c.doc)
+		require.NoError(t, err, "%T", c.factory)
+
+		server, ok := v.(map[string]any)["server"].(map[string]any)
+		require.True(t, ok, "%T: %#v", c.factory, v)
+		assert.NotNil(t, server["port"], "%T", c.factory)
+	}
+}
@@ -1,33 +1,29 @@
-package main
+package factory_method
 
-import "fmt"
+import "github.com/qiye45/go_design_pattern/creational/factory/parser"
 
-// 产品接口
-type Parser interface{ Parse(data string) }
+// Parser 产品接口
+type Parser = parser.Parser
 
-// 工厂接口
+// Factory 工厂接口
 type Factory interface{ Create() Parser }
 
-// json实现
-type JsonParser struct{}
-
-func (JsonParser) Parse(data string) { fmt.Println("json解析", data) }
-
+// JsonFactory json实现
 type JsonFactory struct{}
 
-func (JsonFactory) Create() Parser { return JsonParser{} }
+func (JsonFactory) Create() Parser { return parser.JsonParser{} }
 
-// yaml实现
-type YamlParser struct{}
+// YamlFactory yaml实现
+type YamlFactory struct{}
 
-func (YamlParser) Parse(data string) { fmt.Println("yaml解析", data) }
+func (YamlFactory) Create() Parser { return parser.YamlParser{} }
 
-type YamlFactory struct{}
+// TomlFactory toml实现
+type TomlFactory struct{}
+
+func (TomlFactory) Create() Parser { return parser.TomlParser{} }
 
-func (YamlFactory) Create() Parser { return YamlParser{} }
+// IniFactory ini实现
+type IniFactory struct{}
 
-func main() {
-	var f Factory = JsonFactory{}
-	p := f.Create()
-	p.Parse(`{"a":1}`)
-}
+func (IniFactory) Create() Parser { return parser.IniParser{} }
@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// 支持的格式
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+	FormatINI  = "ini"
+)
+
+// parsers 格式到具体解析器的映射
+var parsers = map[string]Parser{
+	FormatJSON: JsonParser{},
+	FormatYAML: YamlParser{},
+	FormatTOML: TomlParser{},
+	FormatINI:  IniParser{},
+}
+
+var extFormats = map[string]string{
+	".json": FormatJSON,
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".toml": FormatTOML,
+	".ini":  FormatINI,
+	".cfg":  FormatINI,
+}
+
+var mimeFormats = map[string]string{
+	"application/json":   FormatJSON,
+	"text/json":          FormatJSON,
+	"application/yaml":   FormatYAML,
+	"application/x-yaml": FormatYAML,
+	"text/yaml":          FormatYAML,
+	"text/x-yaml":        FormatYAML,
+	"application/toml":   FormatTOML,
+	"text/x-toml":        FormatTOML,
+	"text/x-ini":         FormatINI,
+}
+
+// DetectError 无法确定格式
+// Candidates 为空表示没有任何格式匹配，多于一个表示有歧义
+type DetectError struct {
+	Candidates []string
+}
+
+func (e *DetectError) Error() string {
+	if len(e.Candidates) == 0 {
+		return "cannot detect format"
+	}
+	return fmt.Sprintf("ambiguous format, candidates: %s", strings.Join(e.Candidates, ", "))
+}
+
+var (
+	sectionRe    = regexp.MustCompile(`^\[[^\[\],]+\]$`)
+	arrayTableRe = regexp.MustCompile(`^\[\[[^\[\]]+\]\]$`)
+	yamlKeyRe    = regexp.MustCompile(`^(- |-$|[^=\s][^=]*?:(\s|$))`)
+	assignRe     = regexp.MustCompile(`^[^=:\s][^=]*=`)
+	// 数组表 [[x]]、数组值和内联表只有 TOML 支持
+	tomlOnlyRe = regexp.MustCompile(`(?m)^\s*(\[\[|[^=\n]+=\s*[\[{])`)
+)
+
+// Detect 依次根据文件名后缀、MIME 类型和内容推断格式
+// name 和 mimeType 可以为空；内容推断得到多个候选时，只保留能成功解析的那些
+func Detect(name, mimeType, data string) (string, error) {
+	if f, ok := extFormats[strings.ToLower(filepath.Ext(name))]; ok {
+		return f, nil
+	}
+	if mimeType != "" {
+		if mt, _, err := mime.ParseMediaType(mimeType); err == nil {
+			if f, ok := mimeFormats[mt]; ok {
+				return f, nil
+			}
+			if strings.HasSuffix(mt, "+json") {
+				return FormatJSON, nil
+			}
+			if strings.HasSuffix(mt, "+yaml") {
+				return FormatYAML, nil
+			}
+		}
+	}
+
+	candidates := sniff(data)
+	if len(candidates) > 1 {
+		var ok []string
+		for _, f := range candidates {
+			if _, err := parsers[f].Parse(data); err == nil {
+				ok = append(ok, f)
+			}
+		}
+		// 都解析失败时保留原候选，交给调用方决定
+		if len(ok) > 0 {
+			candidates = ok
+		}
+		switch {
+		// JSON 是 YAML 的子集，两者都能解析时按 JSON 处理
+		case len(candidates) == 2 && candidates[0] == FormatJSON && candidates[1] == FormatYAML:
+			candidates = candidates[:1]
+		case len(candidates) == 2 && candidates[0] == FormatTOML && tomlOnlyRe.MatchString(data):
+			candidates = candidates[:1]
+		// key: value 两者都能解析时按 YAML 处理，只有 YAML 解析失败时才是 INI
+		case len(candidates) == 2 && candidates[0] == FormatYAML && candidates[1] == FormatINI:
+			candidates = candidates[:1]
+		}
+	}
+	if len(candidates) != 1 {
+		return "", &DetectError{Candidates: candidates}
+	}
+	return candidates[0], nil
+}
+
+// sniff 根据第一处有意义的内容给出候选格式
+func sniff(data string) []string {
+	data = strings.TrimPrefix(data, "\uFEFF")
+	for _, raw := range strings.Split(data, "\n") {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			// yaml / toml / ini 都支持 # 注释，继续往下看
+			continue
+		case strings.HasPrefix(line, ";"):
+			return []string{FormatINI}
+		case strings.HasPrefix(line, "{"):
+			return []string{FormatJSON}
+		case line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "%YAML"):
+			return []string{FormatYAML}
+		case arrayTableRe.MatchString(line):
+			return []string{FormatTOML}
+		case sectionRe.MatchString(line):
+			return []string{FormatTOML, FormatINI}
+		case strings.HasPrefix(line, "["):
+			return []string{FormatJSON, FormatYAML}
+		case yamlKeyRe.MatchString(line):
+			// INI 也接受 key: value，留给 Detect 按能否解析决定
+			return []string{FormatYAML, FormatINI}
+		case assignRe.MatchString(line):
+			return []string{FormatTOML, FormatINI}
+		}
+		return nil
+	}
+	return nil
+}
+
+// AutoParser 自动识别格式的解析器
+// Filename、MIMEType 可选，用于优先按后缀或 MIME 类型识别；
+// 解析时不修改 AutoParser，同一个值可以在多个 goroutine 中共用，需要知道识别结果时调用 Detect
+type AutoParser struct {
+	Filename string
+	MIMEType string
+}
+
+// Detect 返回 Parse/Unmarshal 处理 data 时会使用的格式
+func (p *AutoParser) Detect(data string) (string, error) {
+	return Detect(p.Filename, p.MIMEType, data)
+}
+
+func (p *AutoParser) Parse(data string) (any, error) {
+	inner, err := p.parser(data)
+	if err != nil {
+		return nil, err
+	}
+	return inner.Parse(data)
+}
+
+func (p *AutoParser) Unmarshal(data string, v any) error {
+	inner, err := p.parser(data)
+	if err != nil {
+		return err
+	}
+	return inner.Unmarshal(data, v)
+}
+
+func (p *AutoParser) parser(data string) (Parser, error) {
+	f, err := p.Detect(data)
+	if err != nil {
+		return nil, err
+	}
+	return parsers[f], nil
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		name, file, mime, data string
+		want                   string
+	}{
+		{"ext", "app.YML", "", "", FormatYAML},
+		{"ext-wins", "app.toml", "application/json", "{}", FormatTOML},
+		{"mime", "", "application/json; charset=utf-8", "", FormatJSON},
+		{"mime-suffix", "", "application/vnd.api+json", "", FormatJSON},
+		{"object", "", "", "  \n{\"a\": 1}", FormatJSON},
+		{"array", "", "", `[1, 2, 3]`, FormatJSON},
+		{"flow-seq", "", "", "[a, b]\n", FormatYAML},
+		{"yaml-doc", "", "", "# 注释\n---\na: 1\n", FormatYAML},
+		{"yaml-key", "", "", "name: app\nurl: http://x?a=b\n", FormatYAML},
+		{"ini-colon", "", "", "name: app\n[db]\nhost: localhost\n", FormatINI},
+		{"toml-section", "", "", "[server]\nports = [80, 443]\n", FormatTOML},
+		{"toml-array-table", "", "", "[[servers]]\nhost = \"a\"\n", FormatTOML},
+		{"ini-section", "", "", "[server]\nhost = localhost\n", FormatINI},
+		{"ini-comment", "", "", "; 配置\nport = 80\n", FormatINI},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Detect(c.file, c.mime, c.data)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestDetectAmbiguous(t *testing.T) {
+	_, err := Detect("", "", "port = 8080\n")
+	var de *DetectError
+	require.True(t, errors.As(err, &de))
+	assert.Equal(t, []string{FormatTOML, FormatINI}, de.Candidates)
+	assert.EqualError(t, err, "ambiguous format, candidates: toml, ini")
+
+	_, err = Detect("", "", "[server]\nhost = \"localhost\"\n")
+	require.True(t, errors.As(err, &de))
+	assert.Equal(t, []string{FormatTOML, FormatINI}, de.Candidates)
+
+	_, err = Detect("", "", "   \n")
+	require.True(t, errors.As(err, &de))
+	assert.Empty(t, de.Candidates)
+}
+
+func TestAutoParser(t *testing.T) {
+	p := &AutoParser{}
+	var cfg struct{ Name string }
+	require.NoError(t, p.Unmarshal("[app]\nname = demo\n", &struct{ App *struct{ Name string } }{}))
+	f, err := p.Detect("[app]\nname = demo\n")
+	require.NoError(t, err)
+	assert.Equal(t, FormatINI, f)
+
+	require.NoError(t, p.Unmarshal(`{"Name":"demo"}`, &cfg))
+	assert.Equal(t, "demo", cfg.Name)
+
+	_, err = p.Parse("port = 8080")
+	assert.Error(t, err)
+	_, err = p.Detect("port = 8080")
+	assert.Error(t, err)
+}
+
+func TestAutoParserConcurrent(t *testing.T) {
+	p := &AutoParser{}
+	docs := map[string]string{
+		FormatJSON: `{"a": 1}`,
+		FormatYAML: "a: 1\n",
+		FormatINI:  "; 注释\na = 1\n",
+	}
+	var wg sync.WaitGroup
+	for format, doc := range docs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				_, err := p.Parse(doc)
+				assert.NoError(t, err)
+				f, err := p.Detect(doc)
+				assert.NoError(t, err)
+				assert.Equal(t, format, f)
+			}
+		}()
+	}
+	wg.Wait()
+}
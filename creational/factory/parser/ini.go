@@ -0,0 +1,286 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// IniParser 手写的 INI 解析器
+// 支持 [section]、key = value / key: value、以 ; 或 # 开头的注释，
+// 第一个 section 之前的键放在顶层，不能与 section 重名
+type IniParser struct{}
+
+func (p IniParser) Parse(data string) (any, error) {
+	doc, err := parseIni(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.toMap(), nil
+}
+
+func (p IniParser) Unmarshal(data string, v any) error {
+	doc, err := parseIni(data)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &ParseError{Format: "ini", Msg: fmt.Sprintf("Unmarshal(non-pointer %T)", v)}
+	}
+	return doc.decode(rv.Elem())
+}
+
+type iniEntry struct {
+	key, value   string
+	line, column int // value 的起始位置
+}
+
+type iniSection struct {
+	name    string
+	line    int
+	entries []iniEntry
+}
+
+type iniDoc struct {
+	global   iniSection
+	sections []*iniSection
+}
+
+func parseIni(data string) (*iniDoc, error) {
+	doc := &iniDoc{}
+	index := map[string]*iniSection{}
+	cur := &doc.global
+
+	for i, raw := range strings.Split(data, "\n") {
+		line := i + 1
+		text := strings.TrimRight(raw, " \t\r")
+		trimmed := strings.TrimLeft(text, " \t")
+		col := utf8.RuneCountInString(text[:len(text)-len(trimmed)]) + 1
+
+		switch {
+		case trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#':
+			continue
+		case trimmed[0] == '[':
+			if !strings.HasSuffix(trimmed, "]") {
+				return nil, &ParseError{Format: "ini", Line: line, Column: col + utf8.RuneCountInString(trimmed), Msg: "expected ']' to close section"}
+			}
+			name := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if name == "" {
+				return nil, &ParseError{Format: "ini", Line: line, Column: col, Msg: "empty section name"}
+			}
+			// 同名 section 合并
+			if s, ok := index[name]; ok {
+				cur = s
+				continue
+			}
+			// 顶层的键和 section 在结果中位于同一层，不能重名
+			for _, e := range doc.global.entries {
+				if e.key == name {
+					return nil, &ParseError{Format: "ini", Line: line, Column: col,
+						Msg: fmt.Sprintf("section [%s] conflicts with top-level key %q on line %d", name, e.key, e.line)}
+				}
+			}
+			cur = &iniSection{name: name, line: line}
+			index[name] = cur
+			doc.sections = append(doc.sections, cur)
+		default:
+			sep := strings.IndexAny(trimmed, "=:")
+			if sep < 0 {
+				return nil, &ParseError{Format: "ini", Line: line, Column: col, Msg: "expected 'key = value'"}
+			}
+			key := strings.TrimSpace(trimmed[:sep])
+			if key == "" {
+				return nil, &ParseError{Format: "ini", Line: line, Column: col, Msg: "empty key"}
+			}
+			rest := trimmed[sep+1:]
+			value := strings.TrimSpace(rest)
+			valueCol := col + utf8.RuneCountInString(trimmed[:sep+1]) + utf8.RuneCountInString(rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))])
+			cur.entries = append(cur.entries, iniEntry{key: key, value: unquote(value), line: line, column: valueCol})
+		}
+	}
+	return doc, nil
+}
+
+// unquote 去掉成对的单引号或双引号
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func (s *iniSection) toMap() map[string]any {
+	m := make(map[string]any, len(s.entries))
+	for _, e := range s.entries {
+		m[e.key] = e.value
+	}
+	return m
+}
+
+func (d *iniDoc) toMap() map[string]any {
+	m := d.global.toMap()
+	for _, s := range d.sections {
+		m[s.name] = s.toMap()
+	}
+	return m
+}
+
+// decode 把文档写入 map、any 或结构体
+// 结构体字段按 `ini:"name"` 标签匹配，没有标签时按字段名忽略大小写匹配
+func (d *iniDoc) decode(rv reflect.Value) error {
+	switch {
+	case rv.Kind() == reflect.Interface && rv.NumMethod() == 0:
+		rv.Set(reflect.ValueOf(d.toMap()))
+		return nil
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		if err := d.global.decodeMap(rv); err != nil {
+			return err
+		}
+		for _, s := range d.sections {
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := s.decodeInto(elem); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(s.name).Convert(rv.Type().Key()), elem)
+		}
+		return nil
+	case rv.Kind() == reflect.Struct:
+		if err := d.global.decodeStruct(rv); err != nil {
+			return err
+		}
+		for _, s := range d.sections {
+			f, ok := iniField(rv, s.name)
+			if !ok {
+				continue
+			}
+			if err := s.decodeInto(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return &ParseError{Format: "ini", Msg: fmt.Sprintf("cannot unmarshal into %s", rv.Type())}
+}
+
+// decodeInto 把一个 section 写入结构体、map 或 any
+func (s *iniSection) decodeInto(rv reflect.Value) error {
+	switch {
+	case rv.Kind() == reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return s.decodeInto(rv.Elem())
+	case rv.Kind() == reflect.Interface && rv.NumMethod() == 0:
+		rv.Set(reflect.ValueOf(s.toMap()))
+		return nil
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		return s.decodeMap(rv)
+	case rv.Kind() == reflect.Struct:
+		return s.decodeStruct(rv)
+	}
+	return &ParseError{Format: "ini", Line: s.line, Column: 1, Msg: fmt.Sprintf("cannot unmarshal section [%s] into %s", s.name, rv.Type())}
+}
+
+func (s *iniSection) decodeMap(rv reflect.Value) error {
+	for _, e := range s.entries {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := setIniValue(elem, e); err != nil {
+			return err
+		}
+		rv.SetMapIndex(reflect.ValueOf(e.key).Convert(rv.Type().Key()), elem)
+	}
+	return nil
+}
+
+func (s *iniSection) decodeStruct(rv reflect.Value) error {
+	for _, e := range s.entries {
+		f, ok := iniField(rv, e.key)
+		if !ok {
+			continue
+		}
+		if err := setIniValue(f, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iniField 查找与 key 对应的可导出字段
+func iniField(rv reflect.Value, key string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		if tag, _, _ := strings.Cut(sf.Tag.Get("ini"), ","); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		if strings.EqualFold(name, key) {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setIniValue 把字符串值转换成字段的类型
+func setIniValue(rv reflect.Value, e iniEntry) error {
+	fail := func(err error) error {
+		return &ParseError{Format: "ini", Line: e.line, Column: e.column,
+			Msg: fmt.Sprintf("cannot unmarshal %q into %s (key %q)", e.value, rv.Type(), e.key), Err: err}
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(e.value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(e.value)
+		if err != nil {
+			return fail(err)
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(e.value, 10, rv.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(e.value, 10, rv.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(e.value, rv.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		rv.SetFloat(n)
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return setIniValue(rv.Elem(), e)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return fail(nil)
+		}
+		rv.Set(reflect.ValueOf(e.value))
+	default:
+		return fail(nil)
+	}
+	return nil
+}
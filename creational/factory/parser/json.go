@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// JsonParser 基于标准库 encoding/json
+type JsonParser struct{}
+
+func (p JsonParser) Parse(data string) (any, error) {
+	var v any
+	if err := p.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (JsonParser) Unmarshal(data string, v any) error {
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return jsonError(data, err)
+	}
+	return nil
+}
+
+// jsonError 把 encoding/json 的字节偏移换算成行列号
+func jsonError(data string, err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, col := position(data, int(syntaxErr.Offset))
+		return &ParseError{Format: "json", Line: line, Column: col, Msg: syntaxErr.Error(), Err: err}
+	case errors.As(err, &typeErr):
+		line, col := position(data, int(typeErr.Offset))
+		return &ParseError{Format: "json", Line: line, Column: col, Msg: strings.TrimPrefix(typeErr.Error(), "json: "), Err: err}
+	}
+	return &ParseError{Format: "json", Msg: strings.TrimPrefix(err.Error(), "json: "), Err: err}
+}
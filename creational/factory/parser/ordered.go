@@ -0,0 +1,327 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// MapItem 有序映射中的一项
+type MapItem struct {
+	Key   string
+	Value any
+}
+
+// MapSlice 保留键顺序的映射，ParseOrdered 用它代替 map[string]any
+type MapSlice []MapItem
+
+// Get 按键查找
+func (m MapSlice) Get(key string) (any, bool) {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// OrderedParser 能保留键顺序的解析器
+// 结果中的映射都是 MapSlice，其余与 Parse 相同；JSON 的数字保留为 json.Number 以免丢失精度
+type OrderedParser interface {
+	ParseOrdered(data string) (any, error)
+}
+
+func (p JsonParser) ParseOrdered(data string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrderedJSON(dec)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, jsonError(data, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		line, col := position(data, int(dec.InputOffset()))
+		return nil, &ParseError{Format: "json", Line: line, Column: col, Msg: "invalid character after top-level value", Err: err}
+	}
+	return v, nil
+}
+
+func decodeOrderedJSON(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := MapSlice{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			m = setOrdered(m, key.(string), v)
+		}
+		_, err := dec.Token()
+		return m, err
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			v, err := decodeOrderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return tok, nil
+}
+
+// setOrdered 重复的键保留第一次出现的位置，值以最后一次为准，与 map 的行为一致
+func setOrdered(m MapSlice, key string, v any) MapSlice {
+	for i := range m {
+		if m[i].Key == key {
+			m[i].Value = v
+			return m
+		}
+	}
+	return append(m, MapItem{Key: key, Value: v})
+}
+
+func (p YamlParser) ParseOrdered(data string) (any, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, yamlError(err, nil)
+	}
+	if doc.Kind == 0 {
+		return nil, nil
+	}
+	v, err := (&yamlOrdered{expanding: map[*yaml.Node]bool{}}).decode(&doc)
+	if err != nil {
+		return nil, yamlError(err, &doc)
+	}
+	return v, nil
+}
+
+// yamlOrdered 把 yaml.Node 转换成保持键顺序的值
+// 与 yaml.v3 解码时一样检查自引用的锚点，并限制别名展开的总量，防止别名炸弹耗尽内存
+type yamlOrdered struct {
+	expanding map[*yaml.Node]bool // 正在展开的锚点
+	nodes     int                 // 已转换的节点数
+	aliased   int                 // 其中经别名展开得到的节点数
+}
+
+func (d *yamlOrdered) decode(n *yaml.Node) (any, error) {
+	d.nodes++
+	if len(d.expanding) > 0 {
+		d.aliased++
+	}
+	if d.aliased > 100 && d.nodes > 1000 && float64(d.aliased)/float64(d.nodes) > allowedAliasRatio(d.nodes) {
+		return nil, &ParseError{Format: "yaml", Line: n.Line, Column: n.Column, Msg: "document contains excessive aliasing"}
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return d.decode(n.Content[0])
+	case yaml.AliasNode:
+		if d.expanding[n.Alias] {
+			return nil, &ParseError{Format: "yaml", Line: n.Line, Column: n.Column,
+				Msg: fmt.Sprintf("anchor '%s' value contains itself", n.Value)}
+		}
+		d.expanding[n.Alias] = true
+		v, err := d.decode(n.Alias)
+		delete(d.expanding, n.Alias)
+		return v, err
+	case yaml.SequenceNode:
+		list := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := d.decode(c)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case yaml.MappingNode:
+		m := MapSlice{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, vn := n.Content[i], n.Content[i+1]
+			v, err := d.decode(vn)
+			if err != nil {
+				return nil, err
+			}
+			// 合并键 <<: *base，把被合并映射中缺少的键补进来
+			if k.Tag == "!!merge" {
+				for _, item := range mergeSources(v) {
+					if _, ok := m.Get(item.Key); !ok {
+						m = append(m, item)
+					}
+				}
+				continue
+			}
+			if k.Kind != yaml.ScalarNode {
+				return nil, &ParseError{Format: "yaml", Line: k.Line, Column: k.Column, Msg: "mapping key must be a scalar"}
+			}
+			m = setOrdered(m, k.Value, v)
+		}
+		return m, nil
+	}
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// allowedAliasRatio 别名展开的节点占全部节点的比例上限，取值与 yaml.v3 相同：
+// 小文档允许大量使用别名，超过 400 万个节点后降到 10%
+func allowedAliasRatio(nodes int) float64 {
+	const (
+		small, large = 400_000, 4_000_000
+		high, low    = 0.99, 0.10
+	)
+	switch {
+	case nodes <= small:
+		return high
+	case nodes >= large:
+		return low
+	}
+	return high - (high-low)*float64(nodes-small)/float64(large-small)
+}
+
+func mergeSources(v any) MapSlice {
+	switch v := v.(type) {
+	case MapSlice:
+		return v
+	case []any:
+		var all MapSlice
+		for _, e := range v {
+			all = append(all, mergeSources(e)...)
+		}
+		return all
+	}
+	return nil
+}
+
+func (p TomlParser) ParseOrdered(data string) (any, error) {
+	m := map[string]any{}
+	md, err := toml.Decode(data, &m)
+	if err != nil {
+		return nil, tomlError(data, err)
+	}
+	// MetaData.Keys 按定义顺序给出所有键的路径（数组表的元素不带下标）
+	order := map[string]int{}
+	for i, k := range md.Keys() {
+		if _, ok := order[k.String()]; !ok {
+			order[k.String()] = i
+		}
+	}
+	return orderedTOML(m, "", order), nil
+}
+
+func orderedTOML(v any, path string, order map[string]int) any {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		full := func(k string) string {
+			if path == "" {
+				return toml.Key{k}.String()
+			}
+			return path + "." + toml.Key{k}.String()
+		}
+		slices.SortFunc(keys, func(a, b string) int {
+			ia, oka := order[full(a)]
+			ib, okb := order[full(b)]
+			switch {
+			case oka && okb:
+				return ia - ib
+			case oka:
+				return -1
+			case okb:
+				return 1
+			}
+			return strings.Compare(a, b)
+		})
+		m := make(MapSlice, 0, len(keys))
+		for _, k := range keys {
+			m = append(m, MapItem{Key: k, Value: orderedTOML(v[k], full(k), order)})
+		}
+		return m
+	case []map[string]any:
+		list := make([]any, len(v))
+		for i, e := range v {
+			list[i] = orderedTOML(e, path, order)
+		}
+		return list
+	case []any:
+		list := make([]any, len(v))
+		for i, e := range v {
+			list[i] = orderedTOML(e, path, order)
+		}
+		return list
+	}
+	return v
+}
+
+func (p IniParser) ParseOrdered(data string) (any, error) {
+	doc, err := parseIni(data)
+	if err != nil {
+		return nil, err
+	}
+	m := doc.global.toOrdered()
+	for _, s := range doc.sections {
+		m = setOrdered(m, s.name, s.toOrdered())
+	}
+	return m, nil
+}
+
+func (s *iniSection) toOrdered() MapSlice {
+	m := make(MapSlice, 0, len(s.entries))
+	for _, e := range s.entries {
+		m = setOrdered(m, e.key, e.value)
+	}
+	return m
+}
+
+func (p *AutoParser) ParseOrdered(data string) (any, error) {
+	inner, err := p.parser(data)
+	if err != nil {
+		return nil, err
+	}
+	return inner.(OrderedParser).ParseOrdered(data)
+}
+
+// 编译期检查四种解析器都支持保序解析
+var (
+	_ OrderedParser = JsonParser{}
+	_ OrderedParser = YamlParser{}
+	_ OrderedParser = TomlParser{}
+	_ OrderedParser = IniParser{}
+	_ OrderedParser = &AutoParser{}
+)
+
+// ParseOrdered 尽量保序解析：p 实现了 OrderedParser 时调用 ParseOrdered，否则退回 Parse
+// 第二个返回值表示结果是否保留了键顺序
+func ParseOrdered(p Parser, data string) (any, bool, error) {
+	if op, ok := p.(OrderedParser); ok {
+		v, err := op.ParseOrdered(data)
+		return v, true, err
+	}
+	v, err := p.Parse(data)
+	return v, false, err
+}
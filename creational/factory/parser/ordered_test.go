@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keys 返回有序映射的键
+func keys(t *testing.T, v any) []string {
+	t.Helper()
+	m, ok := v.(MapSlice)
+	require.True(t, ok, "%T", v)
+	var out []string
+	for _, item := range m {
+		out = append(out, item.Key)
+	}
+	return out
+}
+
+func TestParseOrdered(t *testing.T) {
+	cases := []struct {
+		name string
+		p    OrderedParser
+		doc  string
+	}{
+		{"json", JsonParser{}, `{"z":1,"a":{"y":true,"b":null},"m":[1,{"k":"v"}]}`},
+		{"yaml", YamlParser{}, "z: 1\na:\n  y: true\n  b: null\nm:\n  - 1\n  - k: v\n"},
+		{"toml", TomlParser{}, "z = 1\nm = [1, 2]\n[a]\ny = true\nb = \"x\"\n"},
+		{"ini", IniParser{}, "z = 1\nm = x\n[a]\ny = true\nb = x\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := c.p.ParseOrdered(c.doc)
+			require.NoError(t, err)
+			top := keys(t, v)
+			assert.Equal(t, "z", top[0])
+			assert.ElementsMatch(t, []string{"z", "a", "m"}, top)
+
+			a, _ := v.(MapSlice).Get("a")
+			assert.Equal(t, []string{"y", "b"}, keys(t, a))
+		})
+	}
+}
+
+func TestParseOrderedJSONNumbers(t *testing.T) {
+	v, err := JsonParser{}.ParseOrdered(`{"big": 12345678901234567890, "f": 1.50}`)
+	require.NoError(t, err)
+	big, _ := v.(MapSlice).Get("big")
+	assert.Equal(t, json.Number("12345678901234567890"), big)
+
+	_, err = JsonParser{}.ParseOrdered(`{"a": 1} {"b": 2}`)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, 1, pe.Line)
+
+	_, err = JsonParser{}.ParseOrdered(`{"a": `)
+	assert.Error(t, err)
+}
+
+func TestParseOrderedYAMLMerge(t *testing.T) {
+	v, err := YamlParser{}.ParseOrdered("base: &b\n  x: 1\n  y: 2\nchild:\n  <<: *b\n  y: 3\n  z: 4\n")
+	require.NoError(t, err)
+	child, _ := v.(MapSlice).Get("child")
+	assert.Equal(t, MapSlice{{"x", 1}, {"y", 3}, {"z", 4}}, child)
+}
+
+func TestParseOrderedYAMLErrors(t *testing.T) {
+	_, err := YamlParser{}.ParseOrdered("a: 1\n? [k]\n: v\n")
+	assert.EqualError(t, err, "yaml: line 2, column 3: mapping key must be a scalar")
+
+	_, err = YamlParser{}.ParseOrdered("a: &x [*x]\n")
+	assert.EqualError(t, err, "yaml: line 1, column 8: anchor 'x' value contains itself")
+
+	// 同一个锚点在不同分支中多次展开不是循环
+	v, err := YamlParser{}.ParseOrdered("a: &x [1]\nb: [*x, *x]\n")
+	require.NoError(t, err)
+	b, _ := v.(MapSlice).Get("b")
+	assert.Equal(t, []any{[]any{1}, []any{1}}, b)
+}
+
+// 每一层引用上一层九次，完全展开有 9^9 个节点
+func TestParseOrderedYAMLAliasBomb(t *testing.T) {
+	doc := "a0: &a0 [x, x, x, x, x, x, x, x, x]\n"
+	for i := 1; i < 10; i++ {
+		doc += fmt.Sprintf("a%d: &a%d [%s]\n", i, i, strings.TrimSuffix(strings.Repeat(fmt.Sprintf("*a%d, ", i-1), 9), ", "))
+	}
+	_, err := YamlParser{}.ParseOrdered(doc)
+	assert.ErrorContains(t, err, "document contains excessive aliasing")
+}
+
+func TestParseOrderedAutoParser(t *testing.T) {
+	v, ordered, err := ParseOrdered(&AutoParser{}, "b: 1\na: 2\n")
+	require.NoError(t, err)
+	assert.True(t, ordered)
+	assert.Equal(t, []string{"b", "a"}, keys(t, v))
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parser 解析器接口
+// Parse 把文本解析为通用结构（map[string]any / []any / 标量），
+// Unmarshal 把文本解码到调用方提供的结构体（或 map）指针中
+type Parser interface {
+	Parse(data string) (any, error)
+	Unmarshal(data string, v any) error
+}
+
+// ParseError 统一的解析错误，四种格式都用它报告出错位置
+type ParseError struct {
+	Format string // json / yaml / toml / ini
+	Line   int    // 行号，从 1 开始，0 表示未知
+	Column int    // 列号（按字符计），从 1 开始，0 表示未知
+	Msg    string
+	Err    error // 底层库返回的原始错误
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s: line %d, column %d: %s", e.Format, e.Line, e.Column, e.Msg)
+	case e.Line > 0:
+		return fmt.Sprintf("%s: line %d: %s", e.Format, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Format, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// position 把字节偏移换算成行列号
+func position(data string, offset int) (line, column int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	head := data[:offset]
+	line = strings.Count(head, "\n") + 1
+	lineStart := strings.LastIndexByte(head, '\n') + 1
+	column = utf8.RuneCountInString(head[lineStart:])
+	if column == 0 {
+		column = 1
+	}
+	return line, column
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Name   string `json:"name" yaml:"name" toml:"name"`
+	Port   int    `json:"port" yaml:"port" toml:"port"`
+	Server struct {
+		Host  string `json:"host" yaml:"host" toml:"host"`
+		Debug bool   `json:"debug" yaml:"debug" toml:"debug"`
+	} `json:"server" yaml:"server" toml:"server"`
+}
+
+func TestParseIntoStruct(t *testing.T) {
+	cases := []struct {
+		name string
+		p    Parser
+		doc  string
+	}{
+		{"json", JsonParser{}, `{"name":"app","port":8080,"server":{"host":"localhost","debug":true}}`},
+		{"yaml", YamlParser{}, "name: app\nport: 8080\nserver:\n  host: localhost\n  debug: true\n"},
+		{"toml", TomlParser{}, "name = \"app\"\nport = 8080\n[server]\nhost = \"localhost\"\ndebug = true\n"},
+		{"ini", IniParser{}, "name = app\nport = 8080\n\n; 服务配置\n[server]\nhost = localhost\ndebug = true\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var cfg config
+			require.NoError(t, c.p.Unmarshal(c.doc, &cfg))
+			assert.Equal(t, "app", cfg.Name)
+			assert.Equal(t, 8080, cfg.Port)
+			assert.Equal(t, "localhost", cfg.Server.Host)
+			assert.True(t, cfg.Server.Debug)
+
+			v, err := c.p.Parse(c.doc)
+			require.NoError(t, err)
+			m, ok := v.(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, "app", m["name"])
+			assert.IsType(t, map[string]any{}, m["server"])
+		})
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	cases := []struct {
+		name         string
+		p            Parser
+		doc          string
+		line, column int
+	}{
+		{"json", JsonParser{}, "{\n  \"a\": 1,\n  \"b\": ?\n}", 3, 8},
+		{"yaml-duplicate-key", YamlParser{}, "a: 1\nb:\n  c: 1\n  c: 2\n", 4, 3},
+		// yaml.v3 的语法错误只有行号
+		{"yaml-syntax", YamlParser{}, "a: 1\nb: c: d\n", 2, 0},
+		{"toml", TomlParser{}, "a = 1\nb = = 2\n", 2, 5},
+		{"ini", IniParser{}, "a = 1\n  [broken\n", 2, 10},
+		{"ini-key", IniParser{}, "a = 1\njust text\n", 2, 1},
+		{"ini-collision", IniParser{}, "db = 1\n[other]\n [db]\n", 3, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := c.p.Parse(c.doc)
+			var pe *ParseError
+			require.True(t, errors.As(err, &pe), "got %v", err)
+			assert.Equal(t, c.line, pe.Line, pe.Error())
+			assert.Equal(t, c.column, pe.Column, pe.Error())
+		})
+	}
+}
+
+func TestUnmarshalTypeErrorPosition(t *testing.T) {
+	var cfg config
+
+	err := JsonParser{}.Unmarshal("{\n  \"port\": \"x\"\n}", &cfg)
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, 2, pe.Line)
+
+	err = IniParser{}.Unmarshal("name = app\nport =  abc\n", &cfg)
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, "ini: line 2, column 9: cannot unmarshal \"abc\" into int (key \"port\")", pe.Error())
+
+	err = YamlParser{}.Unmarshal("name: app\nport:   abc\n", &cfg)
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, "yaml: line 2, column 9: cannot unmarshal !!str `abc` into int", pe.Error())
+
+	err = YamlParser{}.Unmarshal("name: app\nport: abcdefghijkl\n", &cfg)
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, 7, pe.Column, "过长的值在错误信息中被截断")
+}
+
+func TestIniMap(t *testing.T) {
+	doc := "top = 1\n[db]\nhost = \"127.0.0.1\"\nuser: root\n[db]\nport = 3306\n"
+	v, err := IniParser{}.Parse(doc)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"top": "1",
+		"db":  map[string]any{"host": "127.0.0.1", "user": "root", "port": "3306"},
+	}, v)
+
+	var sections map[string]map[string]string
+	require.NoError(t, IniParser{}.Unmarshal("[a]\nk = v\n", &sections))
+	assert.Equal(t, map[string]map[string]string{"a": {"k": "v"}}, sections)
+}
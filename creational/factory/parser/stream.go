@@ -0,0 +1,246 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Stream 流式解析的拉取式迭代器
+// 每次 Next/Decode 只读取并解析一条记录，数据读完后返回 io.EOF
+type Stream interface {
+	Next() (any, error) // 解析为通用结构
+	Decode(v any) error // 解码到调用方提供的指针
+}
+
+// StreamParser 流式解析器，从 io.Reader 中逐条读取记录
+type StreamParser interface {
+	Stream(r io.Reader) Stream
+}
+
+// DefaultMaxRecordSize NDJSON 单条记录的默认长度上限
+const DefaultMaxRecordSize = 1 << 20
+
+// NdjsonStreamParser 逐行解析 NDJSON（每行一个 JSON 值，空行跳过）
+type NdjsonStreamParser struct {
+	MaxRecordSize int // 单行最大字节数，0 表示 DefaultMaxRecordSize
+}
+
+func (p NdjsonStreamParser) Stream(r io.Reader) Stream {
+	limit := p.MaxRecordSize
+	if limit <= 0 {
+		limit = DefaultMaxRecordSize
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, min(limit, 64*1024)), limit)
+	return &ndjsonStream{sc: sc}
+}
+
+type ndjsonStream struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func (s *ndjsonStream) Next() (any, error) {
+	var v any
+	if err := s.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *ndjsonStream) Decode(v any) error {
+	for s.sc.Scan() {
+		s.line++
+		rec := s.sc.Bytes()
+		if len(bytes.TrimSpace(rec)) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(rec, v); err != nil {
+			pe := jsonError(string(rec), err).(*ParseError)
+			pe.Format = "ndjson"
+			pe.Line = s.line
+			return pe
+		}
+		return nil
+	}
+	if err := s.sc.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return &ParseError{Format: "ndjson", Line: s.line + 1, Msg: "record exceeds max record size", Err: err}
+		}
+		return err
+	}
+	return io.EOF
+}
+
+// YamlStreamParser 逐个解析以 --- 分隔的 YAML 多文档流
+type YamlStreamParser struct{}
+
+func (YamlStreamParser) Stream(r io.Reader) Stream {
+	return &yamlStream{dec: yaml.NewDecoder(r)}
+}
+
+type yamlStream struct {
+	dec *yaml.Decoder
+}
+
+func (s *yamlStream) Next() (any, error) {
+	var v any
+	if err := s.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *yamlStream) Decode(v any) error {
+	var doc yaml.Node
+	if err := s.dec.Decode(&doc); err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return yamlError(err, nil)
+	}
+	return decodeYAML(&doc, v)
+}
+
+// JsonArrayStreamParser 逐个元素解析顶层 JSON 数组，内存占用只与单个元素大小有关
+type JsonArrayStreamParser struct{}
+
+func (JsonArrayStreamParser) Stream(r io.Reader) Stream {
+	lt := &lineTracker{r: r, line: 1}
+	return &jsonArrayStream{dec: json.NewDecoder(lt), lines: lt}
+}
+
+type jsonArrayStream struct {
+	dec     *json.Decoder
+	lines   *lineTracker
+	started bool
+	done    bool
+}
+
+func (s *jsonArrayStream) Next() (any, error) {
+	var v any
+	if err := s.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *jsonArrayStream) Decode(v any) error {
+	if s.done {
+		return io.EOF
+	}
+	if !s.started {
+		s.started = true
+		if err := s.expectDelim('['); err != nil {
+			return err
+		}
+	}
+	if !s.dec.More() {
+		if err := s.expectDelim(']'); err != nil {
+			return err
+		}
+		if _, err := s.dec.Token(); err != io.EOF {
+			return s.errorAt(s.dec.InputOffset(), "unexpected data after top-level array", err)
+		}
+		s.done = true
+		return io.EOF
+	}
+	start := s.dec.InputOffset()
+	if err := s.dec.Decode(v); err != nil {
+		return s.wrap(start, err)
+	}
+	s.lines.forget(s.dec.InputOffset())
+	return nil
+}
+
+func (s *jsonArrayStream) expectDelim(want json.Delim) error {
+	offset := s.dec.InputOffset()
+	tok, err := s.dec.Token()
+	if err != nil {
+		return s.wrap(offset, err)
+	}
+	if tok != want {
+		return s.errorAt(s.dec.InputOffset(), fmt.Sprintf("expected %q, got %v", string(want), tok), nil)
+	}
+	return nil
+}
+
+func (s *jsonArrayStream) wrap(offset int64, err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		return s.errorAt(syntaxErr.Offset, syntaxErr.Error(), err)
+	case errors.As(err, &typeErr):
+		return s.errorAt(typeErr.Offset, strings.TrimPrefix(typeErr.Error(), "json: "), err)
+	case err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF):
+		return s.errorAt(s.lines.read, "unexpected end of JSON input", err)
+	}
+	return err
+}
+
+func (s *jsonArrayStream) errorAt(offset int64, msg string, err error) error {
+	line, col := s.lines.position(offset)
+	return &ParseError{Format: "json", Line: line, Column: max(col, 1), Msg: msg, Err: err}
+}
+
+// lineTracker 记录已读取数据中的换行位置，用于把字节偏移换算成行列号
+// 已解析完的部分通过 forget 丢弃，只保留预读窗口内的数据，内存占用不随输入增长
+type lineTracker struct {
+	r      io.Reader
+	read   int64   // 已读取的字节数
+	base   int64   // tail 的起始偏移，之前的数据都已丢弃
+	line   int     // base 所在的行号（从 1 开始）
+	col    int     // base 之前同一行的字符数
+	starts []int64 // base 之后的行首偏移
+	tail   []byte  // base 之后的原始数据
+}
+
+func (t *lineTracker) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			t.starts = append(t.starts, t.read+int64(i)+1)
+		}
+	}
+	t.tail = append(t.tail, p[:n]...)
+	t.read += int64(n)
+	return n, err
+}
+
+// forget 丢弃 offset 之前的数据
+func (t *lineTracker) forget(offset int64) {
+	t.line, t.col = t.position(offset)
+	i := 0
+	for i < len(t.starts) && t.starts[i] <= offset {
+		i++
+	}
+	t.starts = append(t.starts[:0], t.starts[i:]...)
+	t.tail = append(t.tail[:0], t.tail[offset-t.base:]...)
+	t.base = offset
+}
+
+// position 返回 offset 处的行号和该行到 offset 为止的字符数，offset 早于 base 时按 base 计算
+func (t *lineTracker) position(offset int64) (line, column int) {
+	offset = max(offset, t.base)
+	offset = min(offset, t.read)
+	line, lineStart, prefix := t.line, t.base, t.col
+	for _, s := range t.starts {
+		if s > offset {
+			break
+		}
+		line++
+		lineStart, prefix = s, 0
+	}
+	return line, prefix + utf8.RuneCount(t.tail[lineStart-t.base:offset-t.base])
+}
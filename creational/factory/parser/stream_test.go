@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drain 读完整个流
+func drain(t *testing.T, s Stream) []any {
+	t.Helper()
+	var out []any
+	for {
+		v, err := s.Next()
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+		out = append(out, v)
+	}
+}
+
+func TestNdjsonStream(t *testing.T) {
+	in := "{\"id\":1}\n\n{\"id\":2}\r\n[3]\n"
+	got := drain(t, NdjsonStreamParser{}.Stream(strings.NewReader(in)))
+	assert.Equal(t, []any{
+		map[string]any{"id": float64(1)},
+		map[string]any{"id": float64(2)},
+		[]any{float64(3)},
+	}, got)
+
+	s := NdjsonStreamParser{}.Stream(strings.NewReader("{\"id\":1}\n{\"id\":}\n"))
+	_, err := s.Next()
+	require.NoError(t, err)
+	_, err = s.Next()
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, 2, pe.Line)
+	assert.Equal(t, 7, pe.Column)
+
+	s = NdjsonStreamParser{MaxRecordSize: 8}.Stream(strings.NewReader("{\"name\":\"too long\"}\n"))
+	_, err = s.Next()
+	require.True(t, errors.As(err, &pe))
+	assert.Equal(t, 1, pe.Line)
+}
+
+func TestYamlStream(t *testing.T) {
+	in := "name: a\n---\nname: b\n---\n- 1\n- 2\n"
+	s := YamlStreamParser{}.Stream(strings.NewReader(in))
+
+	var doc struct{ Name string }
+	require.NoError(t, s.Decode(&doc))
+	assert.Equal(t, "a", doc.Name)
+	require.NoError(t, s.Decode(&doc))
+	assert.Equal(t, "b", doc.Name)
+
+	v, err := s.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{1, 2}, v)
+
+	_, err = s.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestJsonArrayStream(t *testing.T) {
+	in := "[\n  {\"id\": 1},\n  {\"id\": 2}\n]\n"
+	got := drain(t, JsonArrayStreamParser{}.Stream(strings.NewReader(in)))
+	assert.Len(t, got, 2)
+
+	got = drain(t, JsonArrayStreamParser{}.Stream(strings.NewReader("[]")))
+	assert.Empty(t, got)
+
+	cases := []struct {
+		name, in     string
+		line, column int
+	}{
+		{"not-array", "\n  {\"id\": 1}", 2, 3},
+		{"bad-element", "[\n  {\"id\": 1},\n  {\"id\": x}\n]", 3, 10},
+		{"trailing", "[1]\n2", 2, 1},
+		{"truncated", "[1,\n 2", 2, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := JsonArrayStreamParser{}.Stream(strings.NewReader(c.in))
+			var err error
+			for err == nil {
+				_, err = s.Next()
+			}
+			var pe *ParseError
+			require.True(t, errors.As(err, &pe), "got %v", err)
+			assert.Equal(t, c.line, pe.Line, pe.Error())
+			assert.Equal(t, c.column, pe.Column, pe.Error())
+		})
+	}
+}
+
+// 大数组逐个元素读取时，行号跟踪器只保留预读窗口
+func TestJsonArrayStreamBoundedMemory(t *testing.T) {
+	const n = 100000
+	r, w := io.Pipe()
+	go func() {
+		fmt.Fprint(w, "[")
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",\n")
+			}
+			fmt.Fprintf(w, `{"id":%d,"name":"item-%d"}`, i, i)
+		}
+		fmt.Fprint(w, "]")
+		w.Close()
+	}()
+
+	s := JsonArrayStreamParser{}.Stream(r).(*jsonArrayStream)
+	count := 0
+	for {
+		var item struct{ ID int }
+		err := s.Decode(&item)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, count, item.ID)
+		count++
+		assert.Less(t, len(s.lines.tail), 64*1024)
+		assert.Less(t, len(s.lines.starts), 64*1024)
+	}
+	assert.Equal(t, n, count)
+}
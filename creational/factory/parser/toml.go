@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TomlParser 基于 github.com/BurntSushi/toml
+type TomlParser struct{}
+
+func (p TomlParser) Parse(data string) (any, error) {
+	v := map[string]any{}
+	if err := p.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (TomlParser) Unmarshal(data string, v any) error {
+	if _, err := toml.Decode(data, v); err != nil {
+		return tomlError(data, err)
+	}
+	return nil
+}
+
+func tomlError(data string, err error) error {
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		line, col := parseErr.Position.Line, parseErr.Position.Col
+		if col == 0 && parseErr.Position.Start > 0 {
+			line, col = position(data, parseErr.Position.Start)
+		}
+		return &ParseError{Format: "toml", Line: line, Column: col, Msg: parseErr.Message, Err: err}
+	}
+	return &ParseError{Format: "toml", Msg: strings.TrimPrefix(err.Error(), "toml: "), Err: err}
+}
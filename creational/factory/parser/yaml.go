@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YamlParser 基于 gopkg.in/yaml.v3
+type YamlParser struct{}
+
+func (p YamlParser) Parse(data string) (any, error) {
+	var v any
+	if err := p.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Unmarshal 先解析成节点树再解码，解码出错时可以从节点中取得列号
+func (YamlParser) Unmarshal(data string, v any) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+		return yamlError(err, nil)
+	}
+	return decodeYAML(&doc, v)
+}
+
+// decodeYAML 把节点树解码到 v，空文档不修改 v
+func decodeYAML(doc *yaml.Node, v any) error {
+	if doc.Kind == 0 {
+		return nil
+	}
+	if err := doc.Decode(v); err != nil {
+		return yamlError(err, doc)
+	}
+	return nil
+}
+
+var (
+	yamlLineRe  = regexp.MustCompile(`^line (\d+): `)
+	yamlValueRe = regexp.MustCompile("`([^`]*)`" + `|mapping key ("(?:[^"\\]|\\.)*")`)
+)
+
+// yamlError yaml.v3 只在错误文本里给出行号，这里把它解析出来
+// 解码阶段的错误再从节点树 root 中找到出错的节点，补上列号；
+// 语法错误发生在建树之前，yaml.v3 不提供列号，root 为 nil
+func yamlError(err error, root *yaml.Node) error {
+	if pe, ok := err.(*ParseError); ok {
+		return pe
+	}
+	var typeErr *yaml.TypeError
+	msg := err.Error()
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		msg = typeErr.Errors[0]
+	}
+	msg = strings.TrimPrefix(msg, "yaml: ")
+
+	pe := &ParseError{Format: "yaml", Msg: msg, Err: err}
+	if m := yamlLineRe.FindStringSubmatch(msg); m != nil {
+		pe.Line, _ = strconv.Atoi(m[1])
+		pe.Msg = msg[len(m[0]):]
+		if root != nil {
+			pe.Column = yamlColumn(root, pe.Line, pe.Msg)
+		}
+	}
+	return pe
+}
+
+// yamlColumn 在 line 行上找出错误信息所指的节点，返回它的列号，找不到时返回 0
+// 错误信息引用了值时（cannot unmarshal !!str `abc`、mapping key "a"）找值相同的标量，
+// 否则找同一行上类型相符的节点
+func yamlColumn(root *yaml.Node, line int, msg string) int {
+	kind := yaml.ScalarNode
+	switch {
+	case strings.Contains(msg, "!!map"):
+		kind = yaml.MappingNode
+	case strings.Contains(msg, "!!seq"):
+		kind = yaml.SequenceNode
+	}
+	want, quoted := "", false
+	if m := yamlValueRe.FindStringSubmatch(msg); m != nil {
+		quoted = true
+		if want = m[1]; m[2] != "" {
+			want, _ = strconv.Unquote(m[2])
+		}
+	}
+	// 过长的值会被截断为前 7 个字符加 ...
+	prefix, truncated := strings.CutSuffix(want, "...")
+
+	column := 0
+	var walk func(n *yaml.Node) bool
+	walk = func(n *yaml.Node) bool {
+		if n.Line == line && n.Kind == kind {
+			switch {
+			case !quoted:
+				// 映射和序列取同一行上最内层的一个
+				column = n.Column
+				if kind == yaml.ScalarNode {
+					return true
+				}
+			case n.Value == want || truncated && strings.HasPrefix(n.Value, prefix):
+				column = n.Column
+				return true
+			}
+		}
+		for _, c := range n.Content {
+			if walk(c) {
+				return true
+			}
+		}
+		return false
+	}
+	walk(root)
+	return column
+}
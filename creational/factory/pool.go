@@ -0,0 +1,188 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrDoubleRelease  = errors.New("factory: product released twice")
+	ErrForeignProduct = errors.New("factory: product does not belong to this pool")
+)
+
+// PoolConfig 对象池配置
+type PoolConfig struct {
+	Reset   func(Product) // 归还时调用，把产品恢复到初始状态，必填
+	MaxIdle int           // 最多缓存多少个空闲产品，0 表示不限制
+	Debug   bool          // 调试模式：检测归还后被修改的产品，并记录未归还产品的获取位置
+}
+
+// PoolStats 对象池统计
+type PoolStats struct {
+	Gets        uint64 // Get 次数
+	Puts        uint64 // 成功 Put 的次数
+	Misses      uint64 // 池中没有空闲产品、调用工厂新建的次数
+	Outstanding int64  // 已取出尚未归还的产品数
+	Idle        int    // 当前空闲的产品数
+}
+
+// Pool 池化工厂：包装一个 Factory，优先复用归还的产品，池空时才调用工厂新建
+// 实现了 Factory 接口，CreateProduct 等价于 Get
+//
+// 取出的就是工厂创建的产品本身，Pool 按产品的身份跟踪取出和归还，
+// 因此产品必须是可比较的类型，通常是指向非空结构体的指针
+type Pool struct {
+	factory Factory
+	cfg     PoolConfig
+
+	mu    sync.Mutex
+	idle  []Product
+	stats PoolStats
+	// 池中产品的状态：true 为已取出，false 为空闲；超过 MaxIdle 被丢弃的产品不再记录
+	leased map[Product]bool
+	// 调试模式下未归还产品的获取位置，以及空闲产品归还时的快照
+	where     map[Product]string
+	snapshots map[Product]string
+}
+
+// NewPool 创建对象池，cfg.Reset 为空时 panic
+// Pool 可以并发使用；池空时 Get 在锁外调用 f.CreateProduct，多个 goroutine 可能同时调用，f 需要自己保证并发安全
+func NewPool(f Factory, cfg PoolConfig) *Pool {
+	if cfg.Reset == nil {
+		panic("factory: NewPool requires a Reset func")
+	}
+	p := &Pool{factory: f, cfg: cfg, leased: make(map[Product]bool)}
+	if cfg.Debug {
+		p.where = make(map[Product]string)
+		p.snapshots = make(map[Product]string)
+	}
+	return p
+}
+
+// Get 取出一个产品，用完后必须 Put 归还
+// 调试模式下，如果取出的空闲产品在归还后被修改过（归还后继续使用），Get 会 panic
+func (p *Pool) Get() Product {
+	where := ""
+	if p.cfg.Debug {
+		where = "unknown"
+		if _, file, line, ok := runtime.Caller(1); ok {
+			where = fmt.Sprintf("%s:%d", file, line)
+		}
+	}
+
+	p.mu.Lock()
+	p.stats.Gets++
+	p.stats.Outstanding++
+	var x Product
+	snapshot := ""
+	if n := len(p.idle); n > 0 {
+		x = p.idle[n-1]
+		p.idle[n-1] = nil
+		p.idle = p.idle[:n-1]
+		p.lease(x, where)
+		snapshot = p.snapshots[x]
+		delete(p.snapshots, x)
+	} else {
+		p.stats.Misses++
+	}
+	p.mu.Unlock()
+
+	if x != nil {
+		if p.cfg.Debug && fmt.Sprintf("%#v", x) != snapshot {
+			panic("factory: product modified after release")
+		}
+		return x
+	}
+	x = p.factory.CreateProduct()
+	if !reflect.TypeOf(x).Comparable() {
+		panic(fmt.Sprintf("factory: pooled product %T is not comparable", x))
+	}
+	p.mu.Lock()
+	p.lease(x, where)
+	p.mu.Unlock()
+	return x
+}
+
+// lease 记录取出的产品，调用方持有锁
+func (p *Pool) lease(x Product, where string) {
+	p.leased[x] = true
+	if p.cfg.Debug {
+		p.where[x] = where
+	}
+}
+
+// CreateProduct 实现 Factory 接口
+func (p *Pool) CreateProduct() Product { return p.Get() }
+
+// ProductType 实现 ProductTyper，返回被包装工厂的产品类型，不会从池中取出产品
+func (p *Pool) ProductType() reflect.Type { return productType(p.factory) }
+
+// Put 归还产品，先调用 Reset 再放回池中
+// 重复归还返回 ErrDoubleRelease，归还其他池的产品返回 ErrForeignProduct；
+// 被 MaxIdle 丢弃的产品不再属于本池，再次归还时同样返回 ErrForeignProduct
+func (p *Pool) Put(x Product) error {
+	if x == nil || !reflect.TypeOf(x).Comparable() {
+		return ErrForeignProduct
+	}
+	p.mu.Lock()
+	leased, ok := p.leased[x]
+	switch {
+	case !ok:
+		p.mu.Unlock()
+		return ErrForeignProduct
+	case !leased:
+		p.mu.Unlock()
+		return ErrDoubleRelease
+	}
+	p.leased[x] = false
+	delete(p.where, x)
+	p.stats.Puts++
+	p.stats.Outstanding--
+	p.mu.Unlock()
+
+	p.cfg.Reset(x)
+	snapshot := ""
+	if p.cfg.Debug {
+		snapshot = fmt.Sprintf("%#v", x)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cfg.MaxIdle > 0 && len(p.idle) >= p.cfg.MaxIdle {
+		delete(p.leased, x)
+		return nil
+	}
+	p.idle = append(p.idle, x)
+	if p.cfg.Debug {
+		p.snapshots[x] = snapshot
+	}
+	return nil
+}
+
+// Stats 返回统计快照
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Idle = len(p.idle)
+	return s
+}
+
+// Leaks 调试模式下返回所有未归还产品的获取位置（file:line），非调试模式返回 nil
+func (p *Pool) Leaks() []string {
+	if !p.cfg.Debug {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	leaks := make([]string, 0, len(p.where))
+	for _, where := range p.where {
+		leaks = append(leaks, where)
+	}
+	sort.Strings(leaks)
+	return leaks
+}
@@ -0,0 +1,150 @@
+package factory
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bufferProduct 分配成本较高的产品
+type bufferProduct struct{ buf []byte }
+
+func (p *bufferProduct) Use() string { return string(p.buf[:0]) + "buffer" }
+
+// bufferFactory 池空时 Pool 在锁外调用工厂，并发测试中可能同时调用，计数用原子操作
+type bufferFactory struct{ created atomic.Int32 }
+
+func (f *bufferFactory) CreateProduct() Product {
+	f.created.Add(1)
+	return &bufferProduct{buf: make([]byte, 0, 64*1024)}
+}
+
+func resetBuffer(p Product) {
+	b := p.(*bufferProduct)
+	b.buf = b.buf[:0]
+}
+
+func TestPoolReuse(t *testing.T) {
+	f := &bufferFactory{}
+	resets := 0
+	pool := NewPool(f, PoolConfig{Reset: func(p Product) { resets++; resetBuffer(p) }})
+
+	a := pool.Get()
+	a.(*bufferProduct).buf = append(a.(*bufferProduct).buf, "dirty"...)
+	require.NoError(t, pool.Put(a))
+
+	b := pool.Get()
+	assert.Same(t, a, b)
+	assert.Empty(t, b.(*bufferProduct).buf)
+	c := pool.Get()
+
+	assert.Equal(t, int32(2), f.created.Load())
+	assert.Equal(t, 1, resets)
+	assert.Equal(t, PoolStats{Gets: 3, Puts: 1, Misses: 2, Outstanding: 2}, pool.Stats())
+
+	require.NoError(t, pool.Put(b))
+	require.NoError(t, pool.Put(c))
+	assert.Equal(t, PoolStats{Gets: 3, Puts: 3, Misses: 2, Outstanding: 0, Idle: 2}, pool.Stats())
+}
+
+func TestPoolMaxIdle(t *testing.T) {
+	pool := NewPool(&bufferFactory{}, PoolConfig{Reset: resetBuffer, MaxIdle: 1})
+	a, b := pool.Get(), pool.Get()
+	require.NoError(t, pool.Put(a))
+	require.NoError(t, pool.Put(b))
+	assert.Equal(t, 1, pool.Stats().Idle)
+}
+
+func TestPoolRequiresReset(t *testing.T) {
+	assert.Panics(t, func() { NewPool(&FactoryA{}, PoolConfig{}) })
+}
+
+// 重复归还和归还其他池的产品在非调试模式下同样报错，统计不受影响
+func TestPoolDoubleRelease(t *testing.T) {
+	pool := NewPool(&bufferFactory{}, PoolConfig{Reset: resetBuffer, MaxIdle: 1})
+	a, b := pool.Get(), pool.Get()
+	require.NoError(t, pool.Put(a))
+	assert.ErrorIs(t, pool.Put(a), ErrDoubleRelease)
+	assert.ErrorIs(t, pool.Put(&bufferProduct{}), ErrForeignProduct)
+	assert.ErrorIs(t, pool.Put(nil), ErrForeignProduct)
+
+	// 超过 MaxIdle 被丢弃的产品不再属于本池
+	require.NoError(t, pool.Put(b))
+	assert.ErrorIs(t, pool.Put(b), ErrForeignProduct)
+	assert.Equal(t, PoolStats{Gets: 2, Puts: 2, Misses: 2, Outstanding: 0, Idle: 1}, pool.Stats())
+
+	// 再次取出后可以正常归还
+	c := pool.Get()
+	assert.Same(t, a, c)
+	assert.NoError(t, pool.Put(c))
+}
+
+func TestPoolDebug(t *testing.T) {
+	pool := NewPool(&bufferFactory{}, PoolConfig{Reset: resetBuffer, Debug: true})
+
+	// 调试模式下取出的仍然是产品本身
+	a := pool.Get().(*bufferProduct)
+	assert.Equal(t, "buffer", a.Use())
+	leaks := pool.Leaks()
+	require.Len(t, leaks, 1)
+	assert.True(t, strings.Contains(leaks[0], "pool_test.go:"), leaks[0])
+
+	require.NoError(t, pool.Put(a))
+	assert.Empty(t, pool.Leaks())
+	assert.ErrorIs(t, pool.Put(a), ErrDoubleRelease)
+
+	// 归还后没有修改的产品可以正常取出
+	b := pool.Get()
+	assert.Same(t, a, b)
+	require.NoError(t, pool.Put(b))
+
+	// 归还后继续写入，下次取出时发现
+	a.buf = append(a.buf, "stale"...)
+	assert.PanicsWithValue(t, "factory: product modified after release", func() { pool.Get() })
+
+	other := NewPool(&bufferFactory{}, PoolConfig{Reset: resetBuffer, Debug: true})
+	assert.ErrorIs(t, other.Put(a), ErrForeignProduct)
+}
+
+type valueProduct struct{ tags []string }
+
+func (valueProduct) Use() string { return "value" }
+
+type valueFactory struct{}
+
+func (valueFactory) CreateProduct() Product { return valueProduct{} }
+
+func TestPoolRequiresComparable(t *testing.T) {
+	pool := NewPool(valueFactory{}, PoolConfig{Reset: func(Product) {}})
+	assert.PanicsWithValue(t, "factory: pooled product factory.valueProduct is not comparable", func() { pool.Get() })
+	assert.ErrorIs(t, pool.Put(valueProduct{}), ErrForeignProduct)
+}
+
+func TestPoolConcurrent(t *testing.T) {
+	pool := NewPool(&bufferFactory{}, PoolConfig{Reset: resetBuffer, Debug: true})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				p := pool.Get()
+				p.Use()
+				if err := pool.Put(p); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	s := pool.Stats()
+	assert.Equal(t, uint64(8000), s.Gets)
+	assert.Equal(t, uint64(8000), s.Puts)
+	assert.Zero(t, s.Outstanding)
+	assert.LessOrEqual(t, s.Misses, uint64(8))
+}
@@ -0,0 +1,388 @@
+package factory
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Profile 运行环境的属性，例如 env=test、region=cn
+type Profile map[string]string
+
+// ParseProfile 解析 "env=test,region=cn" 形式的 profile，只写键时值为 "true"
+func ParseProfile(s string) (Profile, error) {
+	p := Profile{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(part, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok {
+			v = "true"
+		}
+		if k == "" {
+			return nil, fmt.Errorf("profile %q: empty key in %q", s, part)
+		}
+		p[k] = v
+	}
+	return p, nil
+}
+
+func (p Profile) String() string {
+	keys := sortedKeys(p)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + p[k]
+	}
+	return strings.Join(parts, ",")
+}
+
+// Condition 特性开关表达式
+// 支持 key=value、key!=value、单独的 key（值存在且不是 false/0/off）、!、&&、||、括号，
+// 空表达式恒为真
+type Condition struct {
+	src  string
+	root condNode
+}
+
+type condNode interface {
+	match(p Profile) bool
+}
+
+type (
+	condAnd struct{ l, r condNode }
+	condOr  struct{ l, r condNode }
+	condNot struct{ x condNode }
+	condEq  struct {
+		key, value string
+		not        bool
+	}
+	condFlag struct{ key string }
+	condTrue struct{}
+)
+
+func (c condAnd) match(p Profile) bool { return c.l.match(p) && c.r.match(p) }
+func (c condOr) match(p Profile) bool  { return c.l.match(p) || c.r.match(p) }
+func (c condNot) match(p Profile) bool { return !c.x.match(p) }
+func (c condEq) match(p Profile) bool  { return (p[c.key] == c.value) != c.not }
+func (c condTrue) match(Profile) bool  { return true }
+func (c condFlag) match(p Profile) bool {
+	v, ok := p[c.key]
+	return ok && v != "" && v != "false" && v != "0" && v != "off"
+}
+
+// ParseCondition 解析表达式，如 `env=test && region=cn`
+func ParseCondition(s string) (*Condition, error) {
+	c := &Condition{src: strings.TrimSpace(s)}
+	if c.src == "" {
+		c.root = condTrue{}
+		return c, nil
+	}
+	ps := &condParser{src: s}
+	ps.next()
+	root, err := ps.or()
+	if err == nil && ps.tok != "" {
+		err = ps.errorf("unexpected %q", ps.tok)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.root = root
+	return c, nil
+}
+
+// MustParseCondition 解析失败时 panic，用于包级变量
+func MustParseCondition(s string) *Condition {
+	c, err := ParseCondition(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Match 判断 profile 是否满足条件
+func (c *Condition) Match(p Profile) bool { return c.root.match(p) }
+
+func (c *Condition) String() string { return c.src }
+
+// condParser 递归下降解析：
+//
+//	or    = and { "||" and }
+//	and   = unary { "&&" unary }
+//	unary = "!" unary | "(" or ")" | word [ ("=" | "==" | "!=") word ]
+type condParser struct {
+	src string
+	pos int    // 下一个 token 的起始位置
+	tok string // 当前 token，结束时为空
+	at  int    // 当前 token 的位置
+}
+
+func (ps *condParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("condition %q: column %d: %s", ps.src, ps.at+1, fmt.Sprintf(format, args...))
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b == '-' || b == '.' || b == '/' || b == ':' ||
+		'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+func (ps *condParser) next() {
+	for ps.pos < len(ps.src) && (ps.src[ps.pos] == ' ' || ps.src[ps.pos] == '\t') {
+		ps.pos++
+	}
+	ps.at = ps.pos
+	if ps.pos >= len(ps.src) {
+		ps.tok = ""
+		return
+	}
+	for _, op := range []string{"&&", "||", "==", "!=", "=", "!", "(", ")"} {
+		if strings.HasPrefix(ps.src[ps.pos:], op) {
+			ps.tok = op
+			ps.pos += len(op)
+			return
+		}
+	}
+	end := ps.pos
+	for end < len(ps.src) && isWordByte(ps.src[end]) {
+		end++
+	}
+	if end == ps.pos {
+		end++ // 非法字符单独作为一个 token，交给调用方报错
+	}
+	ps.tok = ps.src[ps.pos:end]
+	ps.pos = end
+}
+
+func (ps *condParser) isWord() bool {
+	return ps.tok != "" && isWordByte(ps.tok[0])
+}
+
+func (ps *condParser) or() (condNode, error) {
+	l, err := ps.and()
+	for err == nil && ps.tok == "||" {
+		ps.next()
+		var r condNode
+		if r, err = ps.and(); err == nil {
+			l = condOr{l, r}
+		}
+	}
+	return l, err
+}
+
+func (ps *condParser) and() (condNode, error) {
+	l, err := ps.unary()
+	for err == nil && ps.tok == "&&" {
+		ps.next()
+		var r condNode
+		if r, err = ps.unary(); err == nil {
+			l = condAnd{l, r}
+		}
+	}
+	return l, err
+}
+
+func (ps *condParser) unary() (condNode, error) {
+	switch {
+	case ps.tok == "!":
+		ps.next()
+		x, err := ps.unary()
+		return condNot{x}, err
+	case ps.tok == "(":
+		ps.next()
+		x, err := ps.or()
+		if err != nil {
+			return nil, err
+		}
+		if ps.tok != ")" {
+			return nil, ps.errorf("expected ')'")
+		}
+		ps.next()
+		return x, nil
+	case ps.isWord():
+		key := ps.tok
+		ps.next()
+		if ps.tok != "=" && ps.tok != "==" && ps.tok != "!=" {
+			return condFlag{key}, nil
+		}
+		not := ps.tok == "!="
+		ps.next()
+		if !ps.isWord() {
+			return nil, ps.errorf("expected value after %q", key)
+		}
+		value := ps.tok
+		ps.next()
+		return condEq{key: key, value: value, not: not}, nil
+	case ps.tok == "":
+		return nil, ps.errorf("unexpected end of expression")
+	}
+	return nil, ps.errorf("unexpected %q", ps.tok)
+}
+
+// Selector 按 profile 为每个接口选择工厂实现
+// 同一接口下条件成立的候选必须唯一；都不成立时使用无条件的默认候选
+type Selector struct {
+	candidates map[string][]Candidate
+}
+
+// Candidate 某个接口的一个候选实现
+type Candidate struct {
+	Name      string
+	Condition *Condition
+	Factory   Factory
+}
+
+// ProductTyper 工厂可以声明自己创建的产品类型，Resolve 时就不必调用 CreateProduct
+// Pool 等有状态的工厂应当实现它
+type ProductTyper interface {
+	ProductType() reflect.Type
+}
+
+// productType 工厂创建的产品类型，未声明时调用一次 CreateProduct
+func productType(f Factory) reflect.Type {
+	if pt, ok := f.(ProductTyper); ok {
+		return pt.ProductType()
+	}
+	return reflect.TypeOf(f.CreateProduct())
+}
+
+func NewSelector() *Selector {
+	return &Selector{candidates: make(map[string][]Candidate)}
+}
+
+// Register 为接口 iface 注册候选实现，cond 为空表示默认实现
+func (s *Selector) Register(iface, name, cond string, f Factory) error {
+	c, err := ParseCondition(cond)
+	if err != nil {
+		return err
+	}
+	for _, existing := range s.candidates[iface] {
+		if existing.Name == name {
+			return fmt.Errorf("%s: candidate %q already registered", iface, name)
+		}
+		if c.src == "" && existing.Condition.src == "" {
+			return fmt.Errorf("%s: default already registered as %q", iface, existing.Name)
+		}
+	}
+	s.candidates[iface] = append(s.candidates[iface], Candidate{Name: name, Condition: c, Factory: f})
+	return nil
+}
+
+// Resolve 在启动时为所有接口确定实现，任何接口无法确定时返回错误
+// 同时记录选中工厂的产品类型，没有实现 ProductTyper 的工厂会被调用一次
+func (s *Selector) Resolve(p Profile) (*Resolution, error) {
+	r := &Resolution{
+		Profile: maps.Clone(p),
+		chosen:  make(map[string]Candidate),
+		types:   make(map[string]reflect.Type),
+		all:     make(map[string][]Candidate),
+	}
+	var errs []string
+	for _, iface := range sortedKeys(s.candidates) {
+		r.all[iface] = slices.Clone(s.candidates[iface])
+		var matched []Candidate
+		var def *Candidate
+		for i, c := range s.candidates[iface] {
+			switch {
+			case c.Condition.src == "":
+				def = &s.candidates[iface][i]
+			case c.Condition.Match(p):
+				matched = append(matched, c)
+			}
+		}
+		switch {
+		case len(matched) == 1:
+			r.chosen[iface] = matched[0]
+		case len(matched) > 1:
+			names := make([]string, len(matched))
+			for i, c := range matched {
+				names[i] = c.Name
+			}
+			errs = append(errs, fmt.Sprintf("%s: ambiguous, candidates %s all match", iface, strings.Join(names, ", ")))
+		case def != nil:
+			r.chosen[iface] = *def
+		default:
+			errs = append(errs, fmt.Sprintf("%s: no candidate matches", iface))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("resolve profile %s: %s", p, strings.Join(errs, "; "))
+	}
+	for iface, c := range r.chosen {
+		r.types[iface] = productType(c.Factory)
+	}
+	return r, nil
+}
+
+// Resolution 某个 profile 下每个接口选中的实现，Profile 是 Resolve 时传入的 profile 的副本
+type Resolution struct {
+	Profile Profile
+	chosen  map[string]Candidate
+	types   map[string]reflect.Type // 选中工厂的产品类型
+	all     map[string][]Candidate
+}
+
+// Factory 返回接口选中的工厂，接口未注册时返回 nil
+func (r *Resolution) Factory(iface string) Factory {
+	c, ok := r.chosen[iface]
+	if !ok {
+		return nil
+	}
+	return c.Factory
+}
+
+// Chosen 返回接口选中的候选
+func (r *Resolution) Chosen(iface string) (Candidate, bool) {
+	c, ok := r.chosen[iface]
+	return c, ok
+}
+
+// ProductType 返回接口选中的工厂创建的产品类型，接口未注册时返回 nil
+func (r *Resolution) ProductType(iface string) reflect.Type {
+	return r.types[iface]
+}
+
+// Dump 输出诊断信息：每个接口选中的候选和产品类型，以及未选中的候选
+// 产品类型在 Resolve 时记录，Dump 不调用工厂
+func (r *Resolution) Dump(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "profile: %s\n", r.Profile); err != nil {
+		return err
+	}
+	for _, iface := range sortedKeys(r.chosen) {
+		c := r.chosen[iface]
+		if _, err := fmt.Fprintf(w, "%s -> %s (%v) when %s\n",
+			iface, c.Name, r.types[iface], describe(c.Condition)); err != nil {
+			return err
+		}
+		for _, other := range r.all[iface] {
+			if other.Name == c.Name {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "  skipped %s when %s\n", other.Name, describe(other.Condition)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func describe(c *Condition) string {
+	if c.src == "" {
+		return "<default>"
+	}
+	return c.src
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+package factory
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProfile(t *testing.T) {
+	p, err := ParseProfile(" env=test, region = cn ,beta")
+	require.NoError(t, err)
+	assert.Equal(t, Profile{"env": "test", "region": "cn", "beta": "true"}, p)
+	assert.Equal(t, "beta=true,env=test,region=cn", p.String())
+
+	_, err = ParseProfile("=x")
+	assert.Error(t, err)
+}
+
+func TestCondition(t *testing.T) {
+	p := Profile{"env": "test", "region": "cn", "beta": "on", "legacy": "false"}
+	cases := map[string]bool{
+		"":                                 true,
+		"env=test":                         true,
+		"env==prod":                        false,
+		"env=test && region=cn":            true,
+		"env=test && region!=cn":           false,
+		"env=prod || region=cn":            true,
+		"!(env=prod || region=us)":         true,
+		"beta":                             true,
+		"legacy":                           false,
+		"missing":                          false,
+		"env=prod || env=test && !legacy":  true, // && 优先级高于 ||
+		"(env=prod || env=test) && legacy": false,
+		"zone!=a":                          true,
+	}
+	for expr, want := range cases {
+		c, err := ParseCondition(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, c.Match(p), expr)
+	}
+
+	for expr, msg := range map[string]string{
+		"env=":               `condition "env=": column 5: expected value after "env"`,
+		"env=test &&":        `condition "env=test &&": column 12: unexpected end of expression`,
+		"(env=test":          `condition "(env=test": column 10: expected ')'`,
+		"env=test region=cn": `condition "env=test region=cn": column 10: unexpected "region"`,
+		"env=test & x":       `condition "env=test & x": column 10: unexpected "&"`,
+	} {
+		_, err := ParseCondition(expr)
+		assert.EqualError(t, err, msg, expr)
+	}
+}
+
+func newSelector(t *testing.T) *Selector {
+	s := NewSelector()
+	require.NoError(t, s.Register("Product", "prod", "env=prod", &FactoryA{}))
+	require.NoError(t, s.Register("Product", "mock", "env=test && region=cn", &FactoryB{}))
+	require.NoError(t, s.Register("Product", "default", "", &FactoryA{}))
+	require.NoError(t, s.Register("Cache", "memory", "env=test", &FactoryB{}))
+	require.NoError(t, s.Register("Cache", "redis", "env!=test", &FactoryA{}))
+	return s
+}
+
+func TestSelectorResolve(t *testing.T) {
+	s := newSelector(t)
+
+	r, err := s.Resolve(Profile{"env": "test", "region": "cn"})
+	require.NoError(t, err)
+	assert.Equal(t, "Product B", r.Factory("Product").CreateProduct().Use())
+	c, _ := r.Chosen("Cache")
+	assert.Equal(t, "memory", c.Name)
+
+	r, err = s.Resolve(Profile{"env": "test", "region": "us"})
+	require.NoError(t, err)
+	c, _ = r.Chosen("Product")
+	assert.Equal(t, "default", c.Name)
+	assert.Nil(t, r.Factory("Unknown"))
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Dump(&buf))
+	assert.Equal(t, `profile: env=test,region=us
+Cache -> memory (*factory.ConcreteProductB) when env=test
+  skipped redis when env!=test
+Product -> default (*factory.ConcreteProductA) when <default>
+  skipped prod when env=prod
+  skipped mock when env=test && region=cn
+`, buf.String())
+}
+
+func TestResolutionIsolated(t *testing.T) {
+	pool := NewPool(&FactoryA{}, PoolConfig{Reset: func(Product) {}})
+	s := NewSelector()
+	require.NoError(t, s.Register("Product", "pooled", "", pool))
+	p := Profile{"env": "test"}
+	r, err := s.Resolve(p)
+	require.NoError(t, err)
+
+	p["env"] = "prod"
+	assert.Equal(t, Profile{"env": "test"}, r.Profile, "修改传入的 profile 不影响结果")
+
+	require.NoError(t, r.Dump(io.Discard))
+	assert.Zero(t, pool.Stats().Gets, "Resolve 和 Dump 都不从池中取出产品")
+	assert.Equal(t, reflect.TypeFor[*ConcreteProductA](), r.ProductType("Product"))
+}
+
+func TestSelectorErrors(t *testing.T) {
+	s := newSelector(t)
+	assert.Error(t, s.Register("Product", "prod", "env=staging", &FactoryA{}))
+	assert.Error(t, s.Register("Product", "fallback", "", &FactoryA{}))
+	assert.Error(t, s.Register("Product", "blank", "  ", &FactoryA{}), "空白条件也是默认实现")
+	assert.Error(t, s.Register("Product", "bad", "env=", &FactoryA{}))
+
+	require.NoError(t, s.Register("Cache", "local", "region=cn", &FactoryA{}))
+	_, err := s.Resolve(Profile{"env": "test", "region": "cn"})
+	assert.EqualError(t, err, "resolve profile env=test,region=cn: Cache: ambiguous, candidates memory, local all match")
+
+	s = NewSelector()
+	require.NoError(t, s.Register("Product", "prod", "env=prod", &FactoryA{}))
+	_, err = s.Resolve(Profile{})
+	assert.EqualError(t, err, "resolve profile : Product: no candidate matches")
+}
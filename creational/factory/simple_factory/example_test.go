@@ -0,0 +1,71 @@
+package simple_factory
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleNewParser() {
+	p := NewParser("json")
+	v, err := p.Parse(`{"a":1}`)
+	fmt.Println(v, err)
+	// Output: map[a:1] <nil>
+}
+
+func ExampleAutoParser() {
+	p := NewParser("auto").(*AutoParser)
+	v, err := p.Parse("---\nname: app\n")
+	format, _ := p.Detect("---\nname: app\n")
+	fmt.Println(format, v, err)
+	// Output: yaml map[name:app] <nil>
+}
+
+func ExampleNewStreamParser() {
+	s := NewStreamParser("ndjson").Stream(strings.NewReader("{\"id\":1}\n{\"id\":2}\n"))
+	for {
+		v, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		fmt.Println(v, err)
+	}
+	// Output:
+	// map[id:1] <nil>
+	// map[id:2] <nil>
+}
+
+func TestNewParser(t *testing.T) {
+	docs := map[string]string{
+		"json": `{"name":"app","port":8080}`,
+		"yaml": "name: app\nport: 8080\n",
+		"toml": "name = \"app\"\nport = 8080\n",
+		"ini":  "name = app\nport = 8080\n",
+	}
+	for format, doc := range docs {
+		t.Run(format, func(t *testing.T) {
+			p := NewParser(format)
+			require.NotNil(t, p)
+
+			var cfg struct {
+				Name string `json:"name" yaml:"name" toml:"name" ini:"name"`
+				Port int    `json:"port" yaml:"port" toml:"port" ini:"port"`
+			}
+			require.NoError(t, p.Unmarshal(doc, &cfg))
+			assert.Equal(t, "app", cfg.Name)
+			assert.Equal(t, 8080, cfg.Port)
+		})
+	}
+	assert.Nil(t, NewParser("xml"))
+}
+
+func TestNewStreamParser(t *testing.T) {
+	for _, format := range []string{"ndjson", "jsonl", "yaml", "yml", "json"} {
+		assert.NotNil(t, NewStreamParser(format), format)
+	}
+	assert.Nil(t, NewStreamParser("toml"))
+}
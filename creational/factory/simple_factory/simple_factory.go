@@ -1,31 +1,50 @@
-package main
+package simple_factory
 
-import "fmt"
+import "github.com/qiye45/go_design_pattern/creational/factory/parser"
 
 // Parser 产品接口
-type Parser interface{ Parse(data string) }
-
-// JsonParser 具体产品
-type JsonParser struct{}
-
-func (JsonParser) Parse(data string) { fmt.Println("json解析", data) }
-
-type YamlParser struct{}
-
-func (YamlParser) Parse(data string) { fmt.Println("yaml解析", data) }
-
-// NewParser 简单工厂
+type Parser = parser.Parser
+
+// StreamParser 流式解析器
+type StreamParser = parser.StreamParser
+
+// 具体产品
+type (
+	JsonParser = parser.JsonParser
+	YamlParser = parser.YamlParser
+	TomlParser = parser.TomlParser
+	IniParser  = parser.IniParser
+	AutoParser = parser.AutoParser
+)
+
+// NewParser 简单工厂，未知类型返回 nil
+// "auto" 返回 *AutoParser，按文件名、MIME 类型或内容自动选择具体解析器
 func NewParser(t string) Parser {
 	switch t {
 	case "json":
 		return JsonParser{}
-	case "yaml":
+	case "yaml", "yml":
 		return YamlParser{}
+	case "toml":
+		return TomlParser{}
+	case "ini":
+		return IniParser{}
+	case "auto":
+		return &AutoParser{}
 	}
 	return nil
 }
 
-func main() {
-	p := NewParser("json")
-	p.Parse(`{"a":1}`)
+// NewStreamParser 流式解析器的简单工厂，未知类型返回 nil
+// "ndjson" 逐行读取，"yaml" 逐个读取多文档流，"json" 逐个读取顶层数组的元素
+func NewStreamParser(t string) StreamParser {
+	switch t {
+	case "ndjson", "jsonl":
+		return parser.NdjsonStreamParser{}
+	case "yaml", "yml":
+		return parser.YamlStreamParser{}
+	case "json":
+		return parser.JsonArrayStreamParser{}
+	}
+	return nil
 }
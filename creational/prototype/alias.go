@@ -0,0 +1,124 @@
+package prototype
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Alias 原值与副本共享的一处可变内存
+type Alias struct {
+	Path string       // 字段路径，如 "Skills"、"Items[\"药水\"]"、"Slots[0].Skills"，根值为 "(root)"
+	Kind reflect.Kind // Pointer、Map、Slice 或 Chan
+}
+
+func (a Alias) String() string { return fmt.Sprintf("%s (%s)", a.Path, a.Kind) }
+
+// Within 共享位置是否在 paths 中的某个字段路径之下（包括路径本身），用于放行有意共享的字段
+func (a Alias) Within(paths ...string) bool {
+	for _, p := range paths {
+		if a.Path == p || strings.HasPrefix(a.Path, p+".") || strings.HasPrefix(a.Path, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// Aliases 用反射同时遍历原值和副本（包括未导出字段），找出两者共享的指针、map、切片底层数组和 chan
+// 可以检查任意 Clone 实现是否真的做了深拷贝；只比较结构相同的部分，类型不同的接口值会被跳过。
+// Aliases 不理会字段标签和具体类型，有意共享的字段（浅拷贝字段、写时复制的容器）由调用方按路径放行
+func Aliases(original, clone any) []Alias {
+	w := &aliasWalker{seen: make(map[[2]uintptr]bool)}
+	w.walk(reflect.ValueOf(original), reflect.ValueOf(clone), "")
+	return w.aliases
+}
+
+type aliasWalker struct {
+	aliases []Alias
+	seen    map[[2]uintptr]bool // 已经比较过的指针对，避免循环引用
+}
+
+func (w *aliasWalker) report(path string, kind reflect.Kind) {
+	if path == "" {
+		path = "(root)"
+	}
+	w.aliases = append(w.aliases, Alias{Path: strings.TrimPrefix(path, "."), Kind: kind})
+}
+
+func (w *aliasWalker) walk(a, b reflect.Value, path string) {
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return
+	}
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			return
+		}
+		if a.Pointer() == b.Pointer() {
+			w.report(path, reflect.Pointer)
+			return
+		}
+		pair := [2]uintptr{a.Pointer(), b.Pointer()}
+		if w.seen[pair] {
+			return
+		}
+		w.seen[pair] = true
+		w.walk(a.Elem(), b.Elem(), path)
+	case reflect.Map:
+		if a.IsNil() || b.IsNil() {
+			return
+		}
+		if a.Pointer() == b.Pointer() {
+			w.report(path, reflect.Map)
+			return
+		}
+		for it := a.MapRange(); it.Next(); {
+			if bv := b.MapIndex(it.Key()); bv.IsValid() {
+				w.walk(it.Value(), bv, fmt.Sprintf("%s[%s]", path, mapKey(it.Key())))
+			}
+		}
+	case reflect.Slice:
+		if overlaps(a, b) {
+			w.report(path, reflect.Slice)
+			return
+		}
+		for i := range min(a.Len(), b.Len()) {
+			w.walk(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Array:
+		for i := range a.Len() {
+			w.walk(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Chan:
+		if !a.IsNil() && a.Pointer() == b.Pointer() {
+			w.report(path, reflect.Chan)
+		}
+	case reflect.Interface:
+		w.walk(a.Elem(), b.Elem(), path)
+	case reflect.Struct:
+		for i := range a.NumField() {
+			w.walk(a.Field(i), b.Field(i), path+"."+a.Type().Field(i).Name)
+		}
+	}
+}
+
+// overlaps 两个切片的底层数组（按容量计算）是否有重叠
+func overlaps(a, b reflect.Value) bool {
+	size := a.Type().Elem().Size()
+	if a.Cap() == 0 || b.Cap() == 0 || size == 0 {
+		return false
+	}
+	aStart, bStart := a.Pointer(), b.Pointer()
+	aEnd, bEnd := aStart+uintptr(a.Cap())*size, bStart+uintptr(b.Cap())*size
+	return aStart < bEnd && bStart < aEnd
+}
+
+func mapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return fmt.Sprintf("%q", k.String())
+	}
+	if k.CanInterface() {
+		return fmt.Sprint(k.Interface())
+	}
+	return k.Kind().String()
+}
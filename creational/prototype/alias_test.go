@@ -0,0 +1,71 @@
+package prototype
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type aliasSample struct {
+	Items  map[string][]int
+	Slots  [2]*Character
+	Extra  any
+	Events chan string
+	hidden []byte
+	Self   *aliasSample
+}
+
+func TestAliases(t *testing.T) {
+	items := map[string][]int{"药水": {1, 2, 3}}
+	hero := &Character{Skills: []string{"攻击"}}
+	events := make(chan string)
+	a := &aliasSample{Items: items, Slots: [2]*Character{hero, {Skills: []string{"防御"}}}, Extra: hero.Skills,
+		Events: events, hidden: make([]byte, 4)}
+	a.Self = a
+
+	b := &aliasSample{
+		Items:  map[string][]int{"药水": items["药水"][1:]}, // 子切片与原切片重叠
+		Slots:  [2]*Character{hero, {Skills: a.Slots[1].Skills[:0]}},
+		Extra:  hero.Skills,
+		Events: events,
+		hidden: a.hidden,
+	}
+	b.Self = b
+
+	assert.Equal(t, []Alias{
+		{Path: `Items["药水"]`, Kind: reflect.Slice},
+		{Path: "Slots[0]", Kind: reflect.Pointer},
+		{Path: "Slots[1].Skills", Kind: reflect.Slice},
+		{Path: "Extra", Kind: reflect.Slice},
+		{Path: "Events", Kind: reflect.Chan},
+		{Path: "hidden", Kind: reflect.Slice},
+	}, Aliases(a, b))
+
+	assert.Equal(t, []Alias{{Path: "(root)", Kind: reflect.Pointer}}, Aliases(hero, hero))
+	assert.Empty(t, Aliases(hero, hero.Clone()))
+	// DeepCopy 对 chan 按值复制
+	assert.Equal(t, []Alias{{Path: "Events", Kind: reflect.Chan}}, Aliases(a, DeepCopy(a)))
+	assert.Equal(t, "Skills (slice)", Alias{Path: "Skills", Kind: reflect.Slice}.String())
+
+	type tagged struct {
+		Owner  *Character `deepcopy:"shallow"`
+		Shared []int      `clone:"shallow"`
+		Own    []int
+	}
+	// 标签不影响检查，是否允许共享由调用方决定
+	x := tagged{Owner: hero, Shared: []int{1}, Own: []int{2}}
+	assert.Equal(t, []Alias{
+		{Path: "Owner", Kind: reflect.Pointer},
+		{Path: "Shared", Kind: reflect.Slice},
+		{Path: "Own", Kind: reflect.Slice},
+	}, Aliases(x, x))
+}
+
+func TestAliasWithin(t *testing.T) {
+	assert.True(t, Alias{Path: "Sponsor"}.Within("Sponsor"))
+	assert.True(t, Alias{Path: "Sponsor.Tags"}.Within("Sponsor"))
+	assert.True(t, Alias{Path: `Items["药水"][0]`}.Within("Other", "Items"))
+	assert.False(t, Alias{Path: "SponsorName"}.Within("Sponsor"))
+	assert.False(t, Alias{Path: "Sponsor"}.Within())
+}
@@ -0,0 +1,196 @@
+package prototype
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// COWSlice 写时复制的切片：Clone 只复制切片头，与原值共享底层数组，
+// 任何一方第一次通过 Set、Append、Delete 写入时才复制自己的一份
+//
+// 只能通过 Clone 复制；直接赋值得到的副本与原值共享存储，DeepCopy 得到的副本不再共享。
+// 与切片一样，同一个值不能在多个 goroutine 中同时读写，但可以同时 Clone 已经被 Clone 过的值
+type COWSlice[E any] struct {
+	s     []E
+	owned bool // s 的底层数组只属于当前值，可以原地修改
+}
+
+// NewCOWSlice 返回包含 s 副本的 COWSlice
+func NewCOWSlice[E any](s ...E) COWSlice[E] {
+	return COWSlice[E]{s: slices.Clone(s), owned: true}
+}
+
+// Clone 返回与 c 共享底层数组的副本，c 自己之后的写入也会先复制
+func (c *COWSlice[E]) Clone() COWSlice[E] {
+	if c.owned {
+		c.owned = false
+	}
+	return COWSlice[E]{s: c.s}
+}
+
+func (c *COWSlice[E]) Len() int { return len(c.s) }
+
+// At 返回第 i 个元素
+func (c *COWSlice[E]) At(i int) E { return c.s[i] }
+
+// All 按下标遍历元素
+func (c *COWSlice[E]) All() iter.Seq2[int, E] { return slices.All(c.s) }
+
+// Slice 返回元素的副本
+func (c *COWSlice[E]) Slice() []E { return slices.Clone(c.s) }
+
+// Set 修改第 i 个元素
+func (c *COWSlice[E]) Set(i int, v E) {
+	c.own(0)
+	c.s[i] = v
+}
+
+// Append 在末尾追加元素
+func (c *COWSlice[E]) Append(v ...E) {
+	c.own(len(v))
+	c.s = append(c.s, v...)
+}
+
+// Delete 删除 [i, j) 范围内的元素
+func (c *COWSlice[E]) Delete(i, j int) {
+	c.own(0)
+	c.s = slices.Delete(c.s, i, j)
+}
+
+// own 写入前确保底层数组只属于自己，grow 为即将追加的元素个数
+func (c *COWSlice[E]) own(grow int) {
+	if c.owned {
+		return
+	}
+	s := make([]E, len(c.s), len(c.s)+grow)
+	copy(s, c.s)
+	c.s, c.owned = s, true
+}
+
+// COWMap 写时复制的 map：Clone 与原值共享同一个 map，任何一方第一次通过 Set、Delete 写入时才复制
+// 值按值保存，值中的指针、切片等引用不会写时复制；复制和并发的限制同 COWSlice
+type COWMap[K comparable, V any] struct {
+	m     map[K]V
+	owned bool
+}
+
+// NewCOWMap 返回包含 m 副本的 COWMap，m 为 nil 时返回空的 COWMap
+func NewCOWMap[K comparable, V any](m map[K]V) COWMap[K, V] {
+	c := maps.Clone(m)
+	if c == nil {
+		c = make(map[K]V)
+	}
+	return COWMap[K, V]{m: c, owned: true}
+}
+
+// Clone 返回与 c 共享存储的副本，c 自己之后的写入也会先复制
+func (c *COWMap[K, V]) Clone() COWMap[K, V] {
+	if c.owned {
+		c.owned = false
+	}
+	return COWMap[K, V]{m: c.m}
+}
+
+func (c *COWMap[K, V]) Len() int { return len(c.m) }
+
+// Get 返回 key 对应的值
+func (c *COWMap[K, V]) Get(key K) (V, bool) {
+	v, ok := c.m[key]
+	return v, ok
+}
+
+// All 遍历键值对，顺序不确定
+func (c *COWMap[K, V]) All() iter.Seq2[K, V] { return maps.All(c.m) }
+
+// Set 写入键值对
+func (c *COWMap[K, V]) Set(key K, v V) {
+	c.own()
+	c.m[key] = v
+}
+
+// Delete 删除 key
+func (c *COWMap[K, V]) Delete(key K) {
+	if _, ok := c.m[key]; !ok {
+		return
+	}
+	c.own()
+	delete(c.m, key)
+}
+
+// own 写入前确保 map 只属于自己，零值的 COWMap 在这里分配 map
+func (c *COWMap[K, V]) own() {
+	if c.owned {
+		return
+	}
+	c.m, c.owned = maps.Clone(c.m), true
+	if c.m == nil {
+		c.m = make(map[K]V)
+	}
+}
+
+// Guild 公会：成员名单和仓库可能很大，Clone 只复制集合的引用，第一次修改时才复制
+type Guild struct {
+	Name    string
+	members COWSlice[string]
+	storage COWMap[string, int] // 物品名到数量
+}
+
+var _ Cloneable[*Guild] = (*Guild)(nil)
+
+func init() {
+	// DeepCopy 会完整复制成员和仓库，改用 Clone 保留写时复制
+	RegisterCopier((*Guild).Clone)
+}
+
+// NewGuild 创建公会，members 和 storage 会被复制
+func NewGuild(name string, members []string, storage map[string]int) *Guild {
+	return &Guild{Name: name, members: NewCOWSlice(members...), storage: NewCOWMap(storage)}
+}
+
+// Clone 复制公会，成员和仓库与 g 共享到第一次写入为止
+func (g *Guild) Clone() *Guild {
+	if g == nil {
+		return nil
+	}
+	c := *g
+	c.members = g.members.Clone()
+	c.storage = g.storage.Clone()
+	return &c
+}
+
+// Members 成员名单的副本
+func (g *Guild) Members() []string { return g.members.Slice() }
+
+// Member 第 i 个成员
+func (g *Guild) Member(i int) string { return g.members.At(i) }
+
+func (g *Guild) MemberCount() int { return g.members.Len() }
+
+// AddMember 加入成员
+func (g *Guild) AddMember(names ...string) { g.members.Append(names...) }
+
+// RenameMember 修改第 i 个成员的名字
+func (g *Guild) RenameMember(i int, name string) { g.members.Set(i, name) }
+
+// RemoveMember 移除第 i 个成员
+func (g *Guild) RemoveMember(i int) { g.members.Delete(i, i+1) }
+
+// Item 仓库中物品的数量
+func (g *Guild) Item(name string) int {
+	n, _ := g.storage.Get(name)
+	return n
+}
+
+// Items 遍历仓库中的物品
+func (g *Guild) Items() iter.Seq2[string, int] { return g.storage.All() }
+
+// Store 存入物品，n 为负数时取出，数量为 0 时从仓库中删除
+func (g *Guild) Store(name string, n int) {
+	total := g.Item(name) + n
+	if total <= 0 {
+		g.storage.Delete(name)
+		return
+	}
+	g.storage.Set(name, total)
+}
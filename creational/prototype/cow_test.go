@@ -0,0 +1,237 @@
+package prototype
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sameArray 两个切片是否共享底层数组
+func sameArray[E any](a, b []E) bool {
+	return cap(a) > 0 && cap(b) > 0 && &a[:1][0] == &b[:1][0]
+}
+
+func sameMap[K comparable, V any](a, b map[K]V) bool {
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
+
+func TestCOWSlice(t *testing.T) {
+	src := []string{"a", "b", "c"}
+	orig := NewCOWSlice(src...)
+	src[0] = "x"
+	assert.Equal(t, []string{"a", "b", "c"}, orig.Slice(), "NewCOWSlice 复制了输入")
+
+	c := orig.Clone()
+	assert.True(t, sameArray(orig.s, c.s), "写入前共享底层数组")
+
+	c.Set(0, "z")
+	assert.False(t, sameArray(orig.s, c.s))
+	assert.Equal(t, []string{"a", "b", "c"}, orig.Slice())
+	assert.Equal(t, []string{"z", "b", "c"}, c.Slice())
+
+	// 原值在 Clone 之后写入同样先复制，即使底层数组还有空余容量
+	orig = COWSlice[string]{s: make([]string, 2, 8), owned: true}
+	c = orig.Clone()
+	orig.Append("tail")
+	orig.Set(0, "head")
+	assert.Equal(t, []string{"", ""}, c.Slice())
+	c.Append("other")
+	assert.Equal(t, []string{"head", "", "tail"}, orig.Slice())
+	assert.Equal(t, []string{"", "", "other"}, c.Slice())
+
+	// 副本的副本
+	c2 := c.Clone()
+	c2.Delete(0, 2)
+	assert.Equal(t, []string{"other"}, c2.Slice())
+	assert.Equal(t, 3, c.Len())
+	assert.Equal(t, "other", c.At(2))
+
+	// 已经拥有底层数组时原地修改
+	s := c2.s
+	c2.Set(0, "own")
+	assert.True(t, sameArray(s, c2.s))
+
+	var got []string
+	for i, v := range c.All() {
+		got = append(got, fmt.Sprintf("%d %s", i, v))
+	}
+	assert.Equal(t, []string{"0 ", "1 ", "2 other"}, got)
+}
+
+func TestCOWMap(t *testing.T) {
+	orig := NewCOWMap(map[string]int{"药水": 3})
+	c := orig.Clone()
+	assert.True(t, sameMap(orig.m, c.m))
+
+	c.Delete("不存在")
+	assert.True(t, sameMap(orig.m, c.m), "删除不存在的键不复制")
+
+	c.Set("药水", 1)
+	c.Set("卷轴", 2)
+	assert.False(t, sameMap(orig.m, c.m))
+	n, ok := orig.Get("药水")
+	assert.Equal(t, 3, n)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]int{"药水": 1, "卷轴": 2}, maps.Collect(c.All()))
+
+	c2 := c.Clone()
+	c.Delete("卷轴")
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, 2, c2.Len())
+	orig.Set("金币", 100)
+	assert.Equal(t, map[string]int{"药水": 1}, maps.Collect(c.All()))
+	assert.Equal(t, map[string]int{"药水": 3, "金币": 100}, maps.Collect(orig.All()))
+}
+
+// nil 和零值的容器可以直接写入
+func TestCOWZeroValue(t *testing.T) {
+	var m COWMap[string, int]
+	c := m.Clone()
+	m.Set("a", 1)
+	c.Set("b", 2)
+	assert.Equal(t, map[string]int{"a": 1}, maps.Collect(m.All()))
+	assert.Equal(t, map[string]int{"b": 2}, maps.Collect(c.All()))
+
+	n := NewCOWMap[string, int](nil)
+	n.Set("a", 1)
+	assert.Equal(t, 1, n.Len())
+
+	var s COWSlice[int]
+	s.Append(1)
+	assert.Equal(t, []int{1}, s.Slice())
+
+	g := NewGuild("新公会", nil, nil)
+	g.Store("药水", 1)
+	g.AddMember("甲")
+	assert.Equal(t, 1, g.Item("药水"))
+	assert.Equal(t, []string{"甲"}, g.Members())
+
+	var zero Guild
+	zero.Store("药水", 2)
+	assert.Equal(t, 2, zero.Item("药水"))
+}
+
+func TestGuildClone(t *testing.T) {
+	g := NewGuild("龙之谷", []string{"甲", "乙"}, map[string]int{"药水": 10})
+	c := g.Clone()
+	assert.Equal(t, []Alias{{Path: "members.s", Kind: reflect.Slice}, {Path: "storage.m", Kind: reflect.Map}}, Aliases(g, c))
+	assert.True(t, sameArray(g.members.s, c.members.s))
+	assert.True(t, sameMap(g.storage.m, c.storage.m))
+
+	c.Name = "分会"
+	c.AddMember("丙")
+	c.RenameMember(0, "甲甲")
+	c.Store("药水", -4)
+	c.Store("金币", 50)
+	assert.Equal(t, []string{"甲", "乙"}, g.Members())
+	assert.Equal(t, 10, g.Item("药水"))
+	assert.Equal(t, []string{"甲甲", "乙", "丙"}, c.Members())
+	assert.Equal(t, map[string]int{"药水": 6, "金币": 50}, maps.Collect(c.Items()))
+
+	c.RemoveMember(1)
+	c.Store("药水", -6)
+	assert.Equal(t, 2, c.MemberCount())
+	assert.Equal(t, "丙", c.Member(1))
+	assert.Equal(t, map[string]int{"金币": 50}, maps.Collect(c.Items()))
+
+	// 原型在 Clone 之后修改也不影响副本
+	g.AddMember("丁")
+	assert.Equal(t, []string{"甲甲", "丙"}, c.Members())
+
+	// DeepCopy 通过注册的 Clone 复制，不会绕过写时复制
+	d := DeepCopy(g)
+	d.RenameMember(0, "改")
+	assert.Equal(t, "甲", g.Member(0))
+
+	assert.Nil(t, (*Guild)(nil).Clone())
+}
+
+func TestGuildManager(t *testing.T) {
+	m := NewPrototypeManager[*Guild]()
+	require.NoError(t, m.Register("公会", NewGuild("模板", []string{"会长"}, map[string]int{"金币": 1}),
+		AllowShared("members", "storage")))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g, err := m.Clone("公会")
+			if !assert.NoError(t, err) {
+				return
+			}
+			g.AddMember(fmt.Sprint("成员", i))
+			g.Store("金币", i)
+		}()
+	}
+	wg.Wait()
+
+	g, err := m.Clone("公会")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"会长"}, g.Members())
+	assert.Equal(t, 1, g.Item("金币"))
+}
+
+// deepGuild 与 Guild 内容相同、每次 Clone 都深拷贝的对照组
+type deepGuild struct {
+	Name    string
+	Members []string
+	Storage map[string]int
+}
+
+func (g *deepGuild) Clone() *deepGuild {
+	c := *g
+	c.Members = slices.Clone(g.Members)
+	c.Storage = maps.Clone(g.Storage)
+	return &c
+}
+
+func benchGuilds(n int) (*Guild, *deepGuild) {
+	members := make([]string, n)
+	storage := make(map[string]int, n)
+	for i := range n {
+		members[i] = fmt.Sprint("成员", i)
+		storage[fmt.Sprint("物品", i)] = i
+	}
+	return NewGuild("公会", members, storage), &deepGuild{Name: "公会", Members: members, Storage: storage}
+}
+
+// go test -bench Guild -benchmem
+// 只克隆、只读时写时复制几乎没有开销；每个副本都修改集合时两者相当
+func BenchmarkGuildClone(b *testing.B) {
+	for _, n := range []int{10, 10000} {
+		cow, deep := benchGuilds(n)
+		b.Run(fmt.Sprintf("cow/clone/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				c := cow.Clone()
+				c.Name = "分会"
+			}
+		})
+		b.Run(fmt.Sprintf("deep/clone/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				c := deep.Clone()
+				c.Name = "分会"
+			}
+		})
+		b.Run(fmt.Sprintf("cow/write/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				c := cow.Clone()
+				c.AddMember("新人")
+				c.Store("金币", 1)
+			}
+		})
+		b.Run(fmt.Sprintf("deep/write/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				c := deep.Clone()
+				c.Members = append(c.Members, "新人")
+				c.Storage["金币"]++
+			}
+		})
+	}
+}
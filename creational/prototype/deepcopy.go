@@ -0,0 +1,217 @@
+package prototype
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// DeepCopy 通过反射深拷贝任意值：嵌套结构体、map、切片、数组、指针和接口都会复制，
+// 原值中共享的引用在副本中依然共享，循环引用也能正确复制
+//
+// 结构体字段可以用标签控制复制方式：
+//
+//	Cache map[string]int `deepcopy:"-"`       // 副本中为零值
+//	Owner *Player        `deepcopy:"shallow"` // 副本与原值共享
+//
+// 与 T 同一个包中声明的结构体，未导出字段同样深拷贝，标签也对它们生效；
+// 其他包（包括标准库）类型的未导出字段是它们的内部状态，按值复制（浅拷贝），
+// 例如 time.Time 中的 *Location 保持同一个指针，sync.Mutex、bytes.Buffer 的内部状态不会被逐层复制。
+// chan 和 func 按值复制，某个类型需要特殊处理时用 RegisterCopier 注册
+func DeepCopy[T any](v T) T {
+	s := &copyState{seen: make(map[visit]reflect.Value), pkg: pkgOf(reflect.ValueOf(&v).Elem())}
+	var out T
+	if copied := s.copy(reflect.ValueOf(&v).Elem()); copied.IsValid() {
+		reflect.ValueOf(&out).Elem().Set(copied)
+	}
+	return out
+}
+
+// copiers 自定义复制函数，key 为 reflect.Type
+var copiers sync.Map
+
+// RegisterCopier 为类型 T 注册自定义复制函数，DeepCopy 遇到 T 时直接调用 fn
+func RegisterCopier[T any](fn func(T) T) {
+	copiers.Store(reflect.TypeFor[T](), func(v reflect.Value) reflect.Value {
+		r := fn(v.Interface().(T))
+		return reflect.ValueOf(&r).Elem()
+	})
+	plain.Clear() // 注册的类型可能出现在之前判定为无需深拷贝的类型中
+}
+
+// visit 已复制的引用：同一地址、同一类型（切片还要求同一长度）只复制一次
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type copyState struct {
+	seen map[visit]reflect.Value
+	pkg  string // 要复制的值的类型所在的包，只有这个包中声明的结构体才深拷贝未导出字段
+}
+
+// pkgOf 值的类型所在的包：沿指针、切片、数组、map 的元素和接口的动态类型找到第一个具名类型
+func pkgOf(v reflect.Value) string {
+	t := v.Type()
+	for {
+		if t.Name() != "" {
+			return t.PkgPath()
+		}
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			if v.IsNil() {
+				return ""
+			}
+			v = v.Elem()
+			t = v.Type()
+		default:
+			return ""
+		}
+	}
+}
+
+func (s *copyState) copy(src reflect.Value) reflect.Value {
+	t := src.Type()
+	// 注册了复制函数的类型不会被判定为 plain
+	if isPlain(t, s.pkg) {
+		return src
+	}
+	if fn, ok := copiers.Load(t); ok {
+		return fn.(func(reflect.Value) reflect.Value)(src)
+	}
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return src
+		}
+		key := visit{ptr: src.Pointer(), typ: t}
+		if v, ok := s.seen[key]; ok {
+			return v
+		}
+		dst := reflect.New(t.Elem())
+		s.seen[key] = dst
+		dst.Elem().Set(s.copy(src.Elem()))
+		return dst
+	case reflect.Map:
+		if src.IsNil() {
+			return src
+		}
+		key := visit{ptr: src.Pointer(), typ: t}
+		if v, ok := s.seen[key]; ok {
+			return v
+		}
+		dst := reflect.MakeMapWithSize(t, src.Len())
+		s.seen[key] = dst
+		for it := src.MapRange(); it.Next(); {
+			dst.SetMapIndex(s.copy(it.Key()), s.copy(it.Value()))
+		}
+		return dst
+	case reflect.Slice:
+		if src.IsNil() {
+			return src
+		}
+		key := visit{ptr: src.Pointer(), typ: t, len: src.Len()}
+		if v, ok := s.seen[key]; ok {
+			return v
+		}
+		dst := reflect.MakeSlice(t, src.Len(), src.Cap())
+		s.seen[key] = dst
+		if isPlain(t.Elem(), s.pkg) {
+			reflect.Copy(dst, src)
+			return dst
+		}
+		for i := range src.Len() {
+			dst.Index(i).Set(s.copy(src.Index(i)))
+		}
+		return dst
+	case reflect.Array:
+		dst := reflect.New(t).Elem()
+		for i := range src.Len() {
+			dst.Index(i).Set(s.copy(src.Index(i)))
+		}
+		return dst
+	case reflect.Interface:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.New(t).Elem()
+		dst.Set(s.copy(src.Elem()))
+		return dst
+	case reflect.Struct:
+		dst := reflect.New(t).Elem()
+		dst.Set(src) // 先整体复制，shallow 字段和其他包的未导出字段保持浅拷贝
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() && f.PkgPath != s.pkg {
+				continue
+			}
+			// 未导出字段不能直接 Set，通过 dst 中字段的地址读写
+			fv := reflect.NewAt(f.Type, unsafe.Pointer(dst.Field(i).UnsafeAddr())).Elem()
+			switch f.Tag.Get("deepcopy") {
+			case "-":
+				fv.SetZero()
+			case "shallow":
+			default:
+				fv.Set(s.copy(fv))
+			}
+		}
+		return dst
+	}
+	// chan、func、unsafe.Pointer
+	return src
+}
+
+// plain 缓存类型是否无需深拷贝（不含引用类型字段），key 为 plainKey，值为 bool
+var plain sync.Map
+
+// plainKey 是否深拷贝未导出字段取决于要复制的值所在的包，因此按包分别缓存
+type plainKey struct {
+	typ reflect.Type
+	pkg string
+}
+
+// isPlain 类型中没有需要深拷贝的部分时可以直接按值复制
+func isPlain(t reflect.Type, pkg string) bool {
+	key := plainKey{typ: t, pkg: pkg}
+	if v, ok := plain.Load(key); ok {
+		return v.(bool)
+	}
+	p := computePlain(t, pkg, map[reflect.Type]bool{})
+	plain.Store(key, p)
+	return p
+}
+
+func computePlain(t reflect.Type, pkg string, visiting map[reflect.Type]bool) bool {
+	if _, ok := copiers.Load(t); ok {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return false
+	case reflect.Array:
+		return computePlain(t.Elem(), pkg, visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for i := range t.NumField() {
+			f := t.Field(i)
+			// 其他包的未导出字段按值复制
+			if !f.IsExported() && f.PkgPath != pkg {
+				continue
+			}
+			if f.Tag.Get("deepcopy") == "" && !computePlain(f.Type, pkg, visiting) {
+				return false
+			}
+			// deepcopy:"-" 需要把字段置零，不能整体复制
+			if f.Tag.Get("deepcopy") == "-" {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package prototype
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inventory struct {
+	Items   map[string][]int
+	Slots   [2]*Character
+	Extra   any
+	Created time.Time
+	Cache   map[string]int `deepcopy:"-"`
+	Owner   *Character     `deepcopy:"shallow"`
+	notes   []string
+}
+
+func TestDeepCopyNested(t *testing.T) {
+	hero := &Character{Name: "战士", Level: 3, Skills: []string{"攻击"}}
+	src := inventory{
+		Items:   map[string][]int{"药水": {1, 2}},
+		Slots:   [2]*Character{hero, nil},
+		Extra:   &Resume{Name: "简历"},
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local),
+		Cache:   map[string]int{"k": 1},
+		Owner:   hero,
+		notes:   []string{"n"},
+	}
+	dst := DeepCopy(src)
+
+	assert.Equal(t, src.Items, dst.Items)
+	assert.Equal(t, *src.Slots[0], *dst.Slots[0])
+	assert.Equal(t, src.Extra, dst.Extra)
+	assert.True(t, src.Created.Equal(dst.Created))
+
+	dst.Items["药水"][0] = 99
+	dst.Slots[0].Skills[0] = "治疗"
+	dst.Extra.(*Resume).Name = "改"
+	assert.Equal(t, 1, src.Items["药水"][0])
+	assert.Equal(t, "攻击", hero.Skills[0])
+	assert.Equal(t, "简历", src.Extra.(*Resume).Name)
+
+	assert.Nil(t, dst.Cache, `deepcopy:"-" 字段为零值`)
+	assert.Same(t, hero, dst.Owner, `deepcopy:"shallow" 字段共享`)
+	assert.Equal(t, src.notes, dst.notes)
+	assert.NotSame(t, &src.notes[0], &dst.notes[0], "未导出字段也深拷贝")
+	assert.Nil(t, dst.Slots[1])
+}
+
+func TestDeepCopyUnexported(t *testing.T) {
+	team := &Team{Name: "蓝队"}
+	team.AddScore("甲", 3)
+	c := DeepCopy(team)
+	c.AddScore("甲", 5)
+	assert.Equal(t, 3, team.Score("甲"))
+	assert.Equal(t, 8, c.Score("甲"))
+	assert.Empty(t, Aliases(team, c))
+}
+
+type stamped struct {
+	At    time.Time
+	Buf   *bytes.Buffer
+	Notes []string
+}
+
+// 其他包类型的未导出字段按值复制：time.Time 保持同一个 *Location，仍然可以用 == 比较
+func TestDeepCopyForeignUnexported(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	s := &stamped{At: time.Date(2024, 1, 2, 3, 4, 5, 0, loc), Buf: bytes.NewBufferString("日志"), Notes: []string{"a"}}
+	c := DeepCopy(s)
+	assert.True(t, s.At == c.At)
+	assert.Same(t, loc, c.At.Location())
+	assert.NotSame(t, s.Buf, c.Buf)
+	assert.Equal(t, "日志", c.Buf.String())
+	assert.Equal(t, []Alias{{Path: "At.loc", Kind: reflect.Pointer}, {Path: "Buf.buf", Kind: reflect.Slice}}, Aliases(s, c))
+}
+
+type node struct {
+	Name string
+	Next *node
+	Prev *node
+	Tags map[string]any
+}
+
+func TestDeepCopySharedAndCyclic(t *testing.T) {
+	a := &node{Name: "a"}
+	b := &node{Name: "b", Prev: a}
+	a.Next, b.Next = b, a
+	a.Tags = map[string]any{}
+	a.Tags["self"] = a.Tags
+	b.Tags = a.Tags
+
+	c := DeepCopy(a)
+	require.NotSame(t, a, c)
+	assert.Same(t, c, c.Next.Next, "环上的指针指向副本")
+	assert.Same(t, c, c.Next.Prev)
+	assert.NotSame(t, b, c.Next)
+
+	c.Tags["x"] = 1
+	assert.NotContains(t, a.Tags, "x")
+	assert.Contains(t, c.Next.Tags, "x", "共享的 map 在副本中依然共享")
+	self := c.Tags["self"].(map[string]any)
+	assert.Contains(t, self, "x", "map 自引用指向副本")
+
+	// 指向同一底层数组的切片在副本中依然共享
+	skills := []string{"攻击", "防御"}
+	pair := DeepCopy([2][]string{skills, skills})
+	pair[0][0] = "治疗"
+	assert.Equal(t, "治疗", pair[1][0])
+	assert.Equal(t, "攻击", skills[0])
+}
+
+type ticket struct{ ID int }
+
+func TestRegisterCopier(t *testing.T) {
+	RegisterCopier(func(t *ticket) *ticket { return &ticket{ID: t.ID + 1000} })
+	t.Cleanup(func() {
+		copiers.Delete(reflect.TypeFor[*ticket]())
+		plain.Clear()
+	})
+
+	type order struct {
+		T     *ticket
+		Count int
+	}
+	got := DeepCopy([]order{{T: &ticket{ID: 1}, Count: 2}})
+	assert.Equal(t, 1001, got[0].T.ID)
+	assert.Equal(t, 2, got[0].Count)
+}
+
+func TestDeepCopyNilAndInterface(t *testing.T) {
+	assert.Nil(t, DeepCopy[any](nil))
+	assert.Nil(t, DeepCopy[*Character](nil))
+	assert.Nil(t, DeepCopy[[]string](nil))
+
+	var v any = map[string][]string{"k": {"v"}}
+	c := DeepCopy(v).(map[string][]string)
+	c["k"][0] = "w"
+	assert.Equal(t, "v", v.(map[string][]string)["k"][0])
+
+	ch := make(chan int)
+	fn := func() {}
+	type handles struct {
+		C  chan int
+		Fn func()
+	}
+	h := DeepCopy(handles{C: ch, Fn: fn})
+	assert.Equal(t, ch, h.C, "chan 浅拷贝")
+	assert.NotNil(t, h.Fn)
+}
+
+func TestDeepCopyCharacter(t *testing.T) {
+	warrior := &Character{Name: "战士模板", Level: 1, Skills: []string{"攻击", "防御"}}
+	player := DeepCopy(warrior)
+	player.Skills[0] = "治疗"
+	assert.Equal(t, []string{"攻击", "防御"}, warrior.Skills)
+	assert.Equal(t, warrior.Clone(), DeepCopy(warrior))
+}
@@ -0,0 +1,160 @@
+package prototype
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrPrototypeNotFound 原型不存在
+var ErrPrototypeNotFound = errors.New("prototype: not found")
+
+// PrototypeManager 原型注册表：保存命名的原型，按需返回互相独立的副本，可以并发使用
+type PrototypeManager[T Cloneable[T]] struct {
+	mu     sync.RWMutex
+	protos map[string]T
+	dirs   map[string]string // 由 LoadDir 加载的原型名到目录
+	// migrations[i] 把模板从版本 i+1 升级到 i+2
+	migrations []Migration
+}
+
+func NewPrototypeManager[T Cloneable[T]]() *PrototypeManager[T] {
+	return &PrototypeManager[T]{protos: make(map[string]T), dirs: make(map[string]string)}
+}
+
+// RegisterOption Register 的可选参数
+type RegisterOption func(*registerOptions)
+
+type registerOptions struct {
+	shared []string
+}
+
+// AllowShared 允许副本与原型共享 paths（Alias.Path 的格式）及其下的内存，
+// 用于有意浅拷贝的字段（如 Team.Sponsor）和写时复制的容器（如 Guild 的 members、storage）
+func AllowShared(paths ...string) RegisterOption {
+	return func(o *registerOptions) { o.shared = append(o.shared, paths...) }
+}
+
+// Register 注册原型，同名时覆盖
+// 注册前先试克隆一次，副本与原型共享可变内存（Clone 没有深拷贝）时拒绝注册，AllowShared 列出的路径除外；
+// 保存的是这份副本，之后修改 proto 不会影响注册表
+func (m *PrototypeManager[T]) Register(name string, proto T, opts ...RegisterOption) error {
+	var o registerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c, err := checkedClone(name, proto, o.shared...)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.protos[name] = c
+	delete(m.dirs, name)
+	return nil
+}
+
+// LoadDir 从目录加载原型模板（格式见 LoadTemplates）并注册，同名时覆盖；
+// 旧版本的模板先用 AddMigration 注册的函数升级到当前版本
+// 再次调用即重新加载：上次从该目录加载、现在已经删除的模板会被注销；
+// 已经 Clone 出去的副本不受影响。任何模板出错时注册表保持不变
+func (m *PrototypeManager[T]) LoadDir(dir string) error {
+	m.mu.RLock()
+	migrations := slices.Clone(m.migrations)
+	m.mu.RUnlock()
+	protos, err := LoadTemplates[T](dir, migrations...)
+	if err != nil {
+		return err
+	}
+	for name, p := range protos {
+		if protos[name], err = checkedClone(name, p); err != nil {
+			return fmt.Errorf("load templates %s: %w", dir, err)
+		}
+	}
+	dir = filepath.Clean(dir)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, from := range m.dirs {
+		if _, ok := protos[name]; !ok && from == dir {
+			delete(m.protos, name)
+			delete(m.dirs, name)
+		}
+	}
+	for name, p := range protos {
+		m.protos[name] = p
+		m.dirs[name] = dir
+	}
+	return nil
+}
+
+// AddMigration 注册把模板从版本 from 升级到 from+1 的函数，from 必须等于当前版本，
+// 注册后当前版本加一；之后 LoadDir 加载旧版本的模板时按顺序逐级升级
+func (m *PrototypeManager[T]) AddMigration(from int, fn Migration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current := len(m.migrations) + 1; from != current {
+		return fmt.Errorf("prototype: migration must start from the current schema version %d, got %d", current, from)
+	}
+	m.migrations = append(m.migrations, fn)
+	return nil
+}
+
+// SchemaVersion 模板结构的当前版本，没有注册升级函数时为 1
+func (m *PrototypeManager[T]) SchemaVersion() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.migrations) + 1
+}
+
+// checkedClone 试克隆一次，副本与原型在 shared 之外共享可变内存时返回错误
+func checkedClone[T Cloneable[T]](name string, proto T, shared ...string) (T, error) {
+	c := proto.Clone()
+	var paths []string
+	for _, a := range Aliases(proto, c) {
+		if !a.Within(shared...) {
+			paths = append(paths, a.String())
+		}
+	}
+	if len(paths) > 0 {
+		var zero T
+		return zero, fmt.Errorf("prototype %q: Clone shares memory with the original: %s", name, strings.Join(paths, ", "))
+	}
+	return c, nil
+}
+
+// Unregister 删除原型
+func (m *PrototypeManager[T]) Unregister(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.protos, name)
+	delete(m.dirs, name)
+}
+
+// Clone 返回原型的新副本，原型不存在时返回 ErrPrototypeNotFound
+func (m *PrototypeManager[T]) Clone(name string) (T, error) {
+	m.mu.RLock()
+	p, ok := m.protos[name]
+	m.mu.RUnlock()
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %q", ErrPrototypeNotFound, name)
+	}
+	// 注册表中的原型不会被修改，可以在锁外克隆
+	return p.Clone(), nil
+}
+
+// Names 已注册的原型名称
+func (m *PrototypeManager[T]) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.protos))
+	for name := range m.protos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
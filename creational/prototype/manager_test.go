@@ -0,0 +1,91 @@
+package prototype
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExamplePrototypeManager() {
+	m := NewPrototypeManager[*Character]()
+	_ = m.Register("战士", &Character{Name: "战士模板", Level: 1, Skills: []string{"攻击", "防御"}})
+
+	p1, _ := m.Clone("战士")
+	p1.Name = "张三"
+	p1.Skills = append(p1.Skills, "治疗")
+	p2, _ := m.Clone("战士")
+
+	fmt.Printf("%+v\n%+v\n", p1, p2)
+	// Output:
+	// &{Name:张三 Level:1 Skills:[攻击 防御 治疗]}
+	// &{Name:战士模板 Level:1 Skills:[攻击 防御]}
+}
+
+// party 故意写错的 Clone：Members 与原值共享
+type party struct {
+	Leader  *Character
+	Members []string
+}
+
+func (p *party) Clone() *party {
+	c := *p
+	return &c
+}
+
+func TestManagerRejectsAliasing(t *testing.T) {
+	m := NewPrototypeManager[*party]()
+	err := m.Register("队伍", &party{Leader: &Character{Skills: []string{"攻击"}}, Members: []string{"a"}})
+	assert.EqualError(t, err, `prototype "队伍": Clone shares memory with the original: Leader (ptr), Members (slice)`)
+	assert.Empty(t, m.Names())
+}
+
+func TestManagerIsolation(t *testing.T) {
+	m := NewPrototypeManager[*Character]()
+	proto := &Character{Name: "法师", Skills: []string{"火球"}}
+	require.NoError(t, m.Register("法师", proto))
+
+	// 注册后修改原对象不影响注册表
+	proto.Skills[0] = "冰箭"
+	c, err := m.Clone("法师")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"火球"}, c.Skills)
+
+	require.NoError(t, m.Register("战士", &Character{Name: "战士"}))
+	assert.Equal(t, []string{"战士", "法师"}, m.Names())
+	m.Unregister("战士")
+	_, err = m.Clone("战士")
+	assert.ErrorIs(t, err, ErrPrototypeNotFound)
+	assert.EqualError(t, err, `prototype: not found: "战士"`)
+}
+
+func TestManagerConcurrent(t *testing.T) {
+	m := NewPrototypeManager[*Character]()
+	require.NoError(t, m.Register("战士", &Character{Name: "战士", Skills: []string{"攻击"}}))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				c, err := m.Clone("战士")
+				if assert.NoError(t, err) {
+					c.Skills[0] = "改"
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, m.Register(fmt.Sprint("角色", i), &Character{Skills: []string{"跳跃"}}))
+			m.Names()
+		}()
+	}
+	wg.Wait()
+	c, err := m.Clone("战士")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"攻击"}, c.Skills)
+	assert.Len(t, m.Names(), 9)
+}
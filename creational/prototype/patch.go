@@ -0,0 +1,472 @@
+package prototype
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CloneWith 克隆 proto 后应用补丁，补丁描述副本与原型的差异：
+//   - JSON 对象按 JSON Merge Patch（RFC 7386）处理：{"Name": "张三", "Skills": ["攻击"]}
+//   - JSON 数组按 JSON Patch（RFC 6902）处理：[{"op": "add", "path": "/Skills/-", "value": "治疗"}]
+//
+// 字段名按 json 标签匹配（没有标签时用字段名，不区分大小写），路径中的字段不存在、
+// 值的类型与字段不符时返回错误，此时不返回副本
+func CloneWith[T Cloneable[T]](proto T, patch []byte) (T, error) {
+	c := proto.Clone()
+	v := reflect.ValueOf(&c).Elem()
+	var err error
+	switch data := bytes.TrimSpace(patch); {
+	case len(data) > 0 && data[0] == '{':
+		err = mergePatch(v, data, "")
+	case len(data) > 0 && data[0] == '[':
+		err = jsonPatch(v, data)
+	default:
+		err = errors.New("want a JSON object (merge patch) or array (JSON Patch)")
+	}
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("patch %T: %w", c, err)
+	}
+	return c, nil
+}
+
+// CloneWith 返回原型的新副本并应用补丁，见 CloneWith 函数
+func (m *PrototypeManager[T]) CloneWith(name string, patch []byte) (T, error) {
+	m.mu.RLock()
+	p, ok := m.protos[name]
+	m.mu.RUnlock()
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %q", ErrPrototypeNotFound, name)
+	}
+	return CloneWith(p, patch)
+}
+
+// mergePatch 按 RFC 7386 把 patch 合并到 v（可寻址）：对象逐键合并，null 删除，其他值整个替换
+func mergePatch(v reflect.Value, patch json.RawMessage, path string) error {
+	if string(patch) == "null" {
+		v.SetZero()
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if patch[0] != '{' || json.Unmarshal(patch, &obj) != nil {
+		return decodeInto(v, patch, path)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		} else if path != "" {
+			detach(v)
+		}
+		return mergePatch(v.Elem(), patch, path)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, key := range sortedKeys(obj) {
+			f, err := field(v, key, path)
+			if err != nil {
+				return err
+			}
+			if err := mergePatch(f, obj[key], path+"/"+key); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, key := range sortedKeys(obj) {
+			k := reflect.ValueOf(key).Convert(v.Type().Key())
+			if string(obj[key]) == "null" {
+				v.SetMapIndex(k, reflect.Value{})
+				continue
+			}
+			// map 的元素不可寻址，合并到副本后写回
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if old := v.MapIndex(k); old.IsValid() {
+				elem.Set(old)
+			}
+			if err := mergePatch(elem, obj[key], path+"/"+key); err != nil {
+				return err
+			}
+			v.SetMapIndex(k, elem)
+		}
+		return nil
+	}
+	return decodeInto(v, patch, path)
+}
+
+// patchOp JSON Patch 中的一个操作
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// jsonPatch 按 RFC 6902 依次执行操作，任何操作失败都返回错误
+func jsonPatch(root reflect.Value, patch json.RawMessage) error {
+	dec := json.NewDecoder(bytes.NewReader(patch))
+	dec.DisallowUnknownFields()
+	var ops []patchOp
+	if err := dec.Decode(&ops); err != nil {
+		return err
+	}
+	for i, op := range ops {
+		if err := applyOp(root, op); err != nil {
+			path := "<missing>"
+			if op.Path != nil {
+				path = *op.Path
+			}
+			return fmt.Errorf("op %d (%s %s): %w", i, op.Op, path, err)
+		}
+	}
+	return nil
+}
+
+func applyOp(root reflect.Value, op patchOp) error {
+	if op.Path == nil {
+		return errors.New(`missing "path"`)
+	}
+	tokens, err := parsePointer(*op.Path)
+	if err != nil {
+		return err
+	}
+	needValue := op.Op == "add" || op.Op == "replace" || op.Op == "test"
+	if needValue && op.Value == nil {
+		return errors.New(`missing "value"`)
+	}
+	switch op.Op {
+	case "add", "replace":
+		return setAt(root, tokens, "", op.Value, op.Op == "add")
+	case "remove":
+		return removeAt(root, tokens, "")
+	case "test":
+		cur, err := getAt(root, tokens, "")
+		if err != nil {
+			return err
+		}
+		want := reflect.New(cur.Type()).Elem()
+		if err := decodeInto(want, op.Value, *op.Path); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(cur.Interface(), want.Interface()) {
+			return fmt.Errorf("test failed: value is %s", mustJSON(cur))
+		}
+		return nil
+	case "move", "copy":
+		if op.From == nil {
+			return errors.New(`missing "from"`)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return err
+		}
+		if op.Op == "move" && strings.HasPrefix(*op.Path+"/", *op.From+"/") && *op.Path != *op.From {
+			return errors.New("cannot move a value into one of its children")
+		}
+		cur, err := getAt(root, from, "")
+		if err != nil {
+			return err
+		}
+		// 经过 JSON 编码再写入目标，既复制了值也检查了目标类型
+		data, err := json.Marshal(cur.Interface())
+		if err != nil {
+			return err
+		}
+		if op.Op == "move" {
+			if err := removeAt(root, from, ""); err != nil {
+				return err
+			}
+		}
+		return setAt(root, tokens, "", data, true)
+	}
+	return fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parsePointer 解析 JSON Pointer（RFC 6901），"" 表示整个文档
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid path %q: must start with /", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// walk 沿 tokens 走到最后一级的容器（已解引用，可寻址）并调用 leaf
+// 途经 map 时先在副本上修改再写回，因为 map 的元素不可寻址；途经的指针先 detach
+func walk(v reflect.Value, tokens []string, path string, leaf func(c reflect.Value, tok, path string) error) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return fmt.Errorf("%s: nil pointer", displayPath(path))
+		}
+		if path != "" {
+			detach(v)
+		}
+		v = v.Elem()
+	}
+	if len(tokens) == 1 {
+		return leaf(v, tokens[0], path)
+	}
+	tok, rest, next := tokens[0], tokens[1:], path+"/"+tokens[0]
+	switch v.Kind() {
+	case reflect.Struct:
+		f, err := field(v, tok, path)
+		if err != nil {
+			return err
+		}
+		return walk(f, rest, next, leaf)
+	case reflect.Slice, reflect.Array:
+		i, err := index(v, tok, path, false)
+		if err != nil {
+			return err
+		}
+		return walk(v.Index(i), rest, next, leaf)
+	case reflect.Map:
+		k, err := keyOf(v, tok, path)
+		if err != nil {
+			return err
+		}
+		old := v.MapIndex(k)
+		if !old.IsValid() {
+			return fmt.Errorf("%s: no such key", displayPath(next))
+		}
+		elem := reflect.New(old.Type()).Elem()
+		elem.Set(old)
+		if err := walk(elem, rest, next, leaf); err != nil {
+			return err
+		}
+		v.SetMapIndex(k, elem)
+		return nil
+	}
+	return fmt.Errorf("%s: cannot index %s", displayPath(path), v.Type())
+}
+
+// detach 让指针 v（可寻址、非 nil）指向原对象的深拷贝，之后通过它写入不会影响原型：
+// 副本中的指针可能与原型共享（如 clone:"shallow" 字段）。根对象是 Clone 的结果，调用方不需要 detach
+func detach(v reflect.Value) {
+	if !v.CanSet() {
+		return
+	}
+	s := &copyState{seen: make(map[visit]reflect.Value), pkg: pkgOf(v)}
+	v.Set(s.copy(v))
+}
+
+func getAt(root reflect.Value, tokens []string, path string) (reflect.Value, error) {
+	if len(tokens) == 0 {
+		return root, nil
+	}
+	var out reflect.Value
+	err := walk(root, tokens, path, func(c reflect.Value, tok, path string) error {
+		switch c.Kind() {
+		case reflect.Struct:
+			f, err := field(c, tok, path)
+			out = f
+			return err
+		case reflect.Slice, reflect.Array:
+			i, err := index(c, tok, path, false)
+			if err == nil {
+				out = c.Index(i)
+			}
+			return err
+		case reflect.Map:
+			k, err := keyOf(c, tok, path)
+			if err != nil {
+				return err
+			}
+			if out = c.MapIndex(k); !out.IsValid() {
+				return fmt.Errorf("%s/%s: no such key", path, tok)
+			}
+			return nil
+		}
+		return fmt.Errorf("%s: cannot index %s", displayPath(path), c.Type())
+	})
+	return out, err
+}
+
+// setAt add 或 replace：add 在切片中插入元素（"-" 表示追加），replace 要求目标已经存在
+func setAt(root reflect.Value, tokens []string, path string, raw json.RawMessage, add bool) error {
+	if len(tokens) == 0 {
+		return decodeInto(root, raw, path)
+	}
+	return walk(root, tokens, path, func(c reflect.Value, tok, path string) error {
+		switch c.Kind() {
+		case reflect.Struct:
+			f, err := field(c, tok, path)
+			if err != nil {
+				return err
+			}
+			return decodeInto(f, raw, path+"/"+tok)
+		case reflect.Slice:
+			i, err := index(c, tok, path, add)
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(c.Type().Elem()).Elem()
+			if err := decodeInto(elem, raw, path+"/"+tok); err != nil {
+				return err
+			}
+			if !add {
+				c.Index(i).Set(elem)
+				return nil
+			}
+			grown := reflect.Append(c, elem)
+			reflect.Copy(grown.Slice(i+1, grown.Len()), grown.Slice(i, grown.Len()-1))
+			grown.Index(i).Set(elem)
+			c.Set(grown)
+			return nil
+		case reflect.Array:
+			if add {
+				return fmt.Errorf("%s: cannot add to fixed-size array", displayPath(path))
+			}
+			i, err := index(c, tok, path, false)
+			if err != nil {
+				return err
+			}
+			return decodeInto(c.Index(i), raw, path+"/"+tok)
+		case reflect.Map:
+			k, err := keyOf(c, tok, path)
+			if err != nil {
+				return err
+			}
+			if !add && !c.MapIndex(k).IsValid() {
+				return fmt.Errorf("%s/%s: no such key", path, tok)
+			}
+			elem := reflect.New(c.Type().Elem()).Elem()
+			if err := decodeInto(elem, raw, path+"/"+tok); err != nil {
+				return err
+			}
+			if c.IsNil() {
+				c.Set(reflect.MakeMap(c.Type()))
+			}
+			c.SetMapIndex(k, elem)
+			return nil
+		}
+		return fmt.Errorf("%s: cannot index %s", displayPath(path), c.Type())
+	})
+}
+
+// removeAt 删除切片元素或 map 的键；结构体字段没法删除，置为零值
+func removeAt(root reflect.Value, tokens []string, path string) error {
+	if len(tokens) == 0 {
+		return errors.New("cannot remove the whole document")
+	}
+	return walk(root, tokens, path, func(c reflect.Value, tok, path string) error {
+		switch c.Kind() {
+		case reflect.Struct:
+			f, err := field(c, tok, path)
+			if err == nil {
+				f.SetZero()
+			}
+			return err
+		case reflect.Slice:
+			i, err := index(c, tok, path, false)
+			if err != nil {
+				return err
+			}
+			c.Set(reflect.AppendSlice(c.Slice(0, i), c.Slice(i+1, c.Len())))
+			return nil
+		case reflect.Map:
+			k, err := keyOf(c, tok, path)
+			if err != nil {
+				return err
+			}
+			if !c.MapIndex(k).IsValid() {
+				return fmt.Errorf("%s/%s: no such key", path, tok)
+			}
+			c.SetMapIndex(k, reflect.Value{})
+			return nil
+		}
+		return fmt.Errorf("%s: cannot remove from %s", displayPath(path), c.Type())
+	})
+}
+
+// field 按 json 名称查找结构体的导出字段，先精确匹配再忽略大小写（与 encoding/json 一致）
+func field(v reflect.Value, name, path string) (reflect.Value, error) {
+	fallback := -1
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = sf.Name
+		}
+		if jsonName == name {
+			return v.Field(i), nil
+		}
+		if fallback < 0 && strings.EqualFold(jsonName, name) {
+			fallback = i
+		}
+	}
+	if fallback >= 0 {
+		return v.Field(fallback), nil
+	}
+	return reflect.Value{}, fmt.Errorf("%s/%s: unknown field %q in %s", path, name, name, v.Type())
+}
+
+// index 解析切片下标；allowEnd 时允许 "-" 和 len（add 追加到末尾）
+func index(v reflect.Value, tok, path string, allowEnd bool) (int, error) {
+	n := v.Len()
+	if tok == "-" && allowEnd {
+		return n, nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || i > n || i == n && !allowEnd || tok != strconv.Itoa(i) {
+		return 0, fmt.Errorf("%s/%s: invalid index for length %d", path, tok, n)
+	}
+	return i, nil
+}
+
+func keyOf(v reflect.Value, tok, path string) (reflect.Value, error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}, fmt.Errorf("%s: map key type %s is not supported", displayPath(path), v.Type().Key())
+	}
+	return reflect.ValueOf(tok).Convert(v.Type().Key()), nil
+}
+
+// decodeInto 把 JSON 值解码到 v（可寻址），未知字段和类型不符都是错误
+func decodeInto(v reflect.Value, raw json.RawMessage, path string) error {
+	p := reflect.New(v.Type())
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p.Interface()); err != nil {
+		return fmt.Errorf("%s: %w", displayPath(path), err)
+	}
+	v.Set(p.Elem())
+	return nil
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+func mustJSON(v reflect.Value) string {
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package prototype
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleCloneWith() {
+	warrior := &Character{Name: "战士模板", Level: 1, Skills: []string{"攻击", "防御"}}
+
+	player1, _ := CloneWith(warrior, []byte(`{"name": "张三", "level": 5}`))
+	player2, _ := CloneWith(warrior, []byte(`[
+		{"op": "replace", "path": "/Name", "value": "李四"},
+		{"op": "add", "path": "/Skills/-", "value": "治疗"}
+	]`))
+	_, err := CloneWith(warrior, []byte(`{"levl": 5}`))
+
+	fmt.Printf("%+v\n%+v\n%+v\n%v\n", player1, player2, warrior, err)
+	// Output:
+	// &{Name:张三 Level:5 Skills:[攻击 防御]}
+	// &{Name:李四 Level:1 Skills:[攻击 防御 治疗]}
+	// &{Name:战士模板 Level:1 Skills:[攻击 防御]}
+	// patch *prototype.Character: /levl: unknown field "levl" in prototype.Character
+}
+
+func newTeam() *Team {
+	return &Team{
+		Name:    "蓝队",
+		Leader:  &Character{Name: "队长", Skills: []string{"指挥"}},
+		Members: []*Character{{Name: "甲"}, {Name: "乙"}},
+		Roles:   map[string][]string{"甲": {"坦克"}, "乙": {"治疗"}},
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	team := newTeam()
+	c, err := CloneWith(team, []byte(`{
+		"Leader": {"Level": 9, "skills": null},
+		"Roles": {"甲": null, "丙": ["输出"]},
+		"Members": [{"Name": "丁"}],
+		"Sponsor": {"Name": "赞助商"}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Character{Name: "队长", Level: 9}, c.Leader)
+	assert.Equal(t, map[string][]string{"乙": {"治疗"}, "丙": {"输出"}}, c.Roles)
+	assert.Equal(t, []*Character{{Name: "丁"}}, c.Members)
+	assert.Equal(t, &Resume{Name: "赞助商"}, c.Sponsor)
+	assert.Equal(t, newTeam(), team, "原型不受影响")
+
+	c, err = CloneWith(team, []byte(`{"Leader": null}`))
+	require.NoError(t, err)
+	assert.Nil(t, c.Leader)
+}
+
+func TestJSONPatch(t *testing.T) {
+	team := newTeam()
+	c, err := CloneWith(team, []byte(`[
+		{"op": "test", "path": "/Name", "value": "蓝队"},
+		{"op": "replace", "path": "/Leader/Name", "value": "新队长"},
+		{"op": "add", "path": "/Members/0", "value": {"Name": "首发"}},
+		{"op": "remove", "path": "/Members/2"},
+		{"op": "add", "path": "/Roles/乙/-", "value": "辅助"},
+		{"op": "copy", "from": "/Roles/乙", "path": "/Roles/丙"},
+		{"op": "move", "from": "/Roles/甲/0", "path": "/Leader/Skills/0"},
+		{"op": "add", "path": "/Roles/a~1b", "value": []}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, &Character{Name: "新队长", Skills: []string{"坦克", "指挥"}}, c.Leader)
+	assert.Equal(t, []*Character{{Name: "首发"}, {Name: "甲"}}, c.Members)
+	assert.Equal(t, map[string][]string{"甲": {}, "乙": {"治疗", "辅助"}, "丙": {"治疗", "辅助"}, "a/b": {}}, c.Roles)
+	assert.Equal(t, newTeam(), team, "原型不受影响")
+}
+
+func TestPatchSharedPointer(t *testing.T) {
+	// Sponsor 是 clone:"shallow" 字段，副本与原型共享同一个对象
+	team := newTeam()
+	team.Sponsor = &Resume{Name: "赞助商", Age: 3}
+
+	c, err := CloneWith(team, []byte(`{"Sponsor": {"Name": "merge"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Resume{Name: "merge", Age: 3}, c.Sponsor)
+
+	c, err = CloneWith(team, []byte(`[{"op": "replace", "path": "/Sponsor/Name", "value": "json"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, &Resume{Name: "json", Age: 3}, c.Sponsor)
+
+	assert.Equal(t, &Resume{Name: "赞助商", Age: 3}, team.Sponsor, "原型不受影响")
+}
+
+func TestPatchErrors(t *testing.T) {
+	tests := []struct {
+		name, patch, err string
+	}{
+		{"not json", `"Name"`, "want a JSON object (merge patch) or array (JSON Patch)"},
+		{"merge unknown field", `{"Leader": {"Levl": 1}}`, `/Leader/Levl: unknown field "Levl" in prototype.Character`},
+		{"merge wrong type", `{"Leader": {"Level": "高"}}`, "/Leader/Level: json: cannot unmarshal string"},
+		{"merge unknown nested", `{"Members": [{"Nme": "x"}]}`, `/Members: json: unknown field "Nme"`},
+		{"unexported", `{"scores": {}}`, `unknown field "scores" in prototype.Team`},
+		{"unknown op", `[{"op": "merge", "path": "/Name"}]`, `op 0 (merge /Name): unknown op "merge"`},
+		{"unknown op field", `[{"op": "add", "path": "/Name", "valeu": 1}]`, `unknown field "valeu"`},
+		{"missing value", `[{"op": "add", "path": "/Name"}]`, `missing "value"`},
+		{"missing path", `[{"op": "remove"}]`, `op 0 (remove <missing>): missing "path"`},
+		{"bad pointer", `[{"op": "remove", "path": "Name"}]`, `invalid path "Name": must start with /`},
+		{"unknown path", `[{"op": "replace", "path": "/Leader/Levl", "value": 1}]`, `/Leader/Levl: unknown field "Levl"`},
+		{"wrong value type", `[{"op": "replace", "path": "/Members/0/Level", "value": "一"}]`, "/Members/0/Level: json: cannot unmarshal string"},
+		{"index out of range", `[{"op": "replace", "path": "/Members/2", "value": {}}]`, "/Members/2: invalid index for length 2"},
+		{"leading zero", `[{"op": "remove", "path": "/Members/01"}]`, "/Members/01: invalid index"},
+		{"missing key", `[{"op": "replace", "path": "/Roles/丙", "value": []}]`, "/Roles/丙: no such key"},
+		{"nil pointer", `[{"op": "replace", "path": "/Sponsor/Name", "value": "x"}]`, "/Sponsor: nil pointer"},
+		{"scalar index", `[{"op": "add", "path": "/Name/0", "value": "x"}]`, "/Name: cannot index string"},
+		{"test failed", `[{"op": "test", "path": "/Leader/Skills", "value": []}]`, `test failed: value is ["指挥"]`},
+		{"move into child", `[{"op": "move", "from": "/Leader", "path": "/Leader/Skills"}]`, "cannot move a value into one of its children"},
+		{"remove root", `[{"op": "remove", "path": ""}]`, "cannot remove the whole document"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := CloneWith(newTeam(), []byte(tt.patch))
+			require.Error(t, err)
+			assert.Nil(t, c)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestManagerCloneWith(t *testing.T) {
+	m := NewPrototypeManager[*Character]()
+	require.NoError(t, m.Register("战士", &Character{Name: "战士", Skills: []string{"攻击"}}))
+	c, err := m.CloneWith("战士", []byte(`{"Name": "张三"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Character{Name: "张三", Skills: []string{"攻击"}}, c)
+
+	_, err = m.CloneWith("法师", []byte(`{}`))
+	assert.ErrorIs(t, err, ErrPrototypeNotFound)
+}
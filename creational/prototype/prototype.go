@@ -1,10 +1,19 @@
 package prototype
 
-// Cloneable 可复制接口
-type Cloneable interface {
-	Clone() Cloneable
+//go:generate go run ../../cmd/clonegen -type Character,Resume,Team -o prototype_clone.go
+
+// Cloneable 可复制接口，T 一般是实现类型本身，如 *Character 实现 Cloneable[*Character]
+type Cloneable[T any] interface {
+	Clone() T
 }
 
+// 编译期检查
+var (
+	_ Cloneable[*Character] = (*Character)(nil)
+	_ Cloneable[*Resume]    = (*Resume)(nil)
+	_ Cloneable[*Team]      = (*Team)(nil)
+)
+
 // Character 游戏角色
 type Character struct {
 	Name   string
@@ -12,29 +21,29 @@ type Character struct {
 	Skills []string
 }
 
-// Clone 深拷贝角色
-func (c *Character) Clone() *Character {
-	// 复制技能切片
-	skills := make([]string, len(c.Skills))
-	copy(skills, c.Skills)
-
-	return &Character{
-		Name:   c.Name,
-		Level:  c.Level,
-		Skills: skills,
-	}
-}
-
 // Resume 简历
 type Resume struct {
 	Name string
 	Age  int
 }
 
-// Clone 复制简历
-func (r *Resume) Clone() *Resume {
-	return &Resume{
-		Name: r.Name,
-		Age:  r.Age,
+// Team 队伍，Clone 由 clonegen 生成（prototype_clone.go）
+type Team struct {
+	Name    string
+	Leader  *Character
+	Members []*Character
+	Roles   map[string][]string // 成员名到职责
+	Sponsor *Resume             `clone:"shallow"` // 赞助商在所有副本间共享
+	scores  map[string]int      `clone:"-"`       // 比赛积分，副本从零开始
+}
+
+// AddScore 记录成员积分
+func (t *Team) AddScore(member string, n int) {
+	if t.scores == nil {
+		t.scores = make(map[string]int)
 	}
+	t.scores[member] += n
 }
+
+// Score 成员积分
+func (t *Team) Score(member string) int { return t.scores[member] }
@@ -0,0 +1,46 @@
+// Code generated by clonegen -type Character,Resume,Team; DO NOT EDIT.
+
+package prototype
+
+import "slices"
+
+// Clone 返回 Character 的深拷贝
+func (x *Character) Clone() *Character {
+	if x == nil {
+		return nil
+	}
+	c := *x
+	c.Skills = slices.Clone(x.Skills)
+	return &c
+}
+
+// Clone 返回 Resume 的深拷贝
+func (x *Resume) Clone() *Resume {
+	if x == nil {
+		return nil
+	}
+	c := *x
+	return &c
+}
+
+// Clone 返回 Team 的深拷贝
+func (x *Team) Clone() *Team {
+	if x == nil {
+		return nil
+	}
+	c := *x
+	c.Leader = x.Leader.Clone()
+	c.Members = slices.Clone(x.Members)
+	for i1 := range x.Members {
+		c.Members[i1] = x.Members[i1].Clone()
+	}
+	if x.Roles != nil {
+		m2 := make(map[string][]string, len(x.Roles))
+		for k3, v4 := range x.Roles {
+			m2[k3] = slices.Clone(v4)
+		}
+		c.Roles = m2
+	}
+	c.scores = nil
+	return &c
+}
@@ -0,0 +1,54 @@
+package prototype
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamClone(t *testing.T) {
+	leader := &Character{Name: "队长", Skills: []string{"指挥"}}
+	team := &Team{
+		Name:    "蓝队",
+		Leader:  leader,
+		Members: []*Character{leader, {Name: "队员", Skills: []string{"治疗"}}, nil},
+		Roles:   map[string][]string{"队长": {"指挥", "坦克"}},
+		Sponsor: &Resume{Name: "赞助商"},
+	}
+	team.AddScore("队长", 3)
+
+	c := team.Clone()
+	assert.Equal(t, team.Name, c.Name)
+	assert.Equal(t, team.Members, c.Members)
+	assert.Equal(t, team.Roles, c.Roles)
+	// 只有标记为 shallow 的 Sponsor 与原值共享
+	assert.Same(t, team.Sponsor, c.Sponsor)
+	assert.Equal(t, []Alias{{Path: "Sponsor", Kind: reflect.Pointer}}, Aliases(team, c))
+	// clone:"-" 的积分不复制
+	assert.Equal(t, 3, team.Score("队长"))
+	assert.Zero(t, c.Score("队长"))
+
+	c.Members[1].Skills[0] = "复活"
+	c.Roles["队长"][0] = "冲锋"
+	assert.Equal(t, "治疗", team.Members[1].Skills[0])
+	assert.Equal(t, "指挥", team.Roles["队长"][0])
+
+	assert.Nil(t, (*Team)(nil).Clone())
+	assert.Equal(t, &Team{}, (&Team{}).Clone())
+}
+
+func TestManagerRegisterTeam(t *testing.T) {
+	m := NewPrototypeManager[*Team]()
+	team := &Team{Name: "蓝队", Leader: &Character{Name: "队长"}, Sponsor: &Resume{Name: "赞助商"}}
+	// Sponsor 有意共享，需要显式放行
+	assert.EqualError(t, m.Register("蓝队", team),
+		`prototype "蓝队": Clone shares memory with the original: Sponsor (ptr)`)
+	require.NoError(t, m.Register("蓝队", team, AllowShared("Sponsor")))
+
+	c, err := m.Clone("蓝队")
+	require.NoError(t, err)
+	assert.Same(t, team.Sponsor, c.Sponsor)
+	assert.NotSame(t, team.Leader, c.Leader)
+}
@@ -0,0 +1,29 @@
+// Package prototypetest 提供检查 Clone 实现的测试辅助函数
+//
+//	func TestCharacterClone(t *testing.T) {
+//		warrior := &prototype.Character{Skills: []string{"攻击"}}
+//		prototypetest.AssertNoAliasing(t, warrior, warrior.Clone())
+//	}
+package prototypetest
+
+import (
+	"testing"
+
+	"github.com/qiye45/go_design_pattern/creational/prototype"
+)
+
+// AssertNoAliasing 检查 clone 与 original 没有共享的指针、map、切片底层数组和 chan，
+// 每处共享报告一条带字段路径的错误，全部独立时返回 true
+// allowed 列出允许共享的字段路径（如标记为浅拷贝的字段），同时放行其下的子路径
+func AssertNoAliasing(t testing.TB, original, clone any, allowed ...string) bool {
+	t.Helper()
+	ok := true
+	for _, a := range prototype.Aliases(original, clone) {
+		if a.Within(allowed...) {
+			continue
+		}
+		t.Errorf("clone shares memory with original: %s", a)
+		ok = false
+	}
+	return ok
+}
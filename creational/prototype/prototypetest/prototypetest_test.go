@@ -0,0 +1,66 @@
+package prototypetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/qiye45/go_design_pattern/creational/prototype"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder 记录 Errorf 调用而不让测试失败，用来检查辅助函数本身
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+type squad struct {
+	Name    string
+	Leader  *prototype.Character
+	Members []*prototype.Character
+	Roles   map[string][]string
+	Events  chan string
+}
+
+// shallowClone 漏掉深拷贝的 Clone 实现
+func (s *squad) shallowClone() *squad {
+	c := *s
+	c.Members = append([]*prototype.Character(nil), s.Members...)
+	return &c
+}
+
+func TestAssertNoAliasing(t *testing.T) {
+	hero := &prototype.Character{Name: "队长", Skills: []string{"指挥"}}
+	s := &squad{
+		Leader:  hero,
+		Members: []*prototype.Character{hero},
+		Roles:   map[string][]string{"队长": {"指挥"}},
+		Events:  make(chan string),
+	}
+
+	r := recorder{TB: t}
+	assert.False(t, AssertNoAliasing(&r, s, s.shallowClone()))
+	assert.Equal(t, []string{
+		"clone shares memory with original: Leader (ptr)",
+		"clone shares memory with original: Members[0] (ptr)",
+		"clone shares memory with original: Roles (map)",
+		"clone shares memory with original: Events (chan)",
+	}, r.errs)
+
+	r = recorder{TB: t}
+	assert.False(t, AssertNoAliasing(&r, s, s.shallowClone(), "Leader", "Members", "Events"))
+	assert.Equal(t, []string{"clone shares memory with original: Roles (map)"}, r.errs)
+
+	r = recorder{TB: t}
+	assert.True(t, AssertNoAliasing(&r, hero, hero.Clone()))
+	assert.True(t, AssertNoAliasing(&r, s, prototype.DeepCopy(s), "Events"))
+	assert.Empty(t, r.errs)
+
+	// 返回原对象本身的 Clone
+	assert.False(t, AssertNoAliasing(&r, hero, hero))
+	assert.Equal(t, []string{"clone shares memory with original: (root) (ptr)"}, r.errs)
+}
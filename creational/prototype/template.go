@@ -0,0 +1,295 @@
+package prototype
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 模板文件中的特殊键
+const (
+	extendsKey   = "extends" // 父模板名
+	versionKey   = "version" // 模板结构的版本，不写时为 1
+	appendSuffix = "+"       // 列表字段的键名以 + 结尾时追加到父模板的列表
+)
+
+// Migration 把模板字段从某个版本升级到下一个版本，可以直接修改 fields
+// fields 只包含模板自己写出的字段（不含 extends 和 version），继承的模板各自按自己的版本升级
+type Migration func(fields map[string]any) error
+
+// LoadTemplates 读取目录下所有 .yaml/.yml 文件中的原型模板，每个文件是模板名到字段的映射：
+//
+//	战士模板:
+//	  level: 1
+//	  skills: [攻击, 防御]
+//	狂战士:
+//	  extends: 战士模板 # 继承父模板的字段，再用自己的字段覆盖
+//	  skills+: [狂暴]   # 追加父模板中没有的元素；写成 skills 则整个替换
+//
+// 嵌套的映射逐键合并；模板名在多个文件中重复、父模板不存在、继承出现循环、
+// 字段在 T 中不存在时都返回错误
+//
+// 模板可以用 version 键标明结构的版本，当前版本为 len(migrations)+1：
+// migrations[i] 把版本 i+1 升级到 i+2，旧版本的模板在合并前按顺序逐级升级，
+// 版本小于 1 或大于当前版本时返回错误
+func LoadTemplates[T any](dir string, migrations ...Migration) (map[string]T, error) {
+	raw, err := readTemplates(dir)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, name := range sortedKeys(raw) {
+		if err := migrate(raw, name, migrations); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("load templates %s: %w", dir, err)
+	}
+	r := &templateResolver{raw: raw, resolved: make(map[string]map[string]any), failed: make(map[string]error)}
+	out := make(map[string]T, len(raw))
+	for _, name := range sortedKeys(raw) {
+		fields, err := r.resolve(name)
+		if err == nil {
+			out[name], err = decodeTemplate[T](fields)
+			if err != nil {
+				err = fmt.Errorf("%s: template %q: %w", raw[name].file, name, err)
+			}
+		}
+		// 继承链上的错误只报告一次
+		if err != nil && !slices.Contains(errs, err) {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("load templates %s: %w", dir, err)
+	}
+	return out, nil
+}
+
+// rawTemplate 文件中未经合并的模板
+type rawTemplate struct {
+	file   string
+	fields map[string]any
+}
+
+func readTemplates(dir string) (map[string]rawTemplate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	raw := make(map[string]rawTemplate)
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !slices.Contains([]string{".yaml", ".yml"}, filepath.Ext(e.Name())) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var top map[string]any
+		if err := yaml.Unmarshal(data, &top); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		for _, name := range sortedKeys(top) {
+			fields, ok := top[name].(map[string]any)
+			if !ok && top[name] != nil {
+				errs = append(errs, fmt.Errorf("%s: template %q: want a mapping, got %T", path, name, top[name]))
+				continue
+			}
+			if prev, ok := raw[name]; ok {
+				errs = append(errs, fmt.Errorf("%s: template %q already defined in %s", path, name, prev.file))
+				continue
+			}
+			raw[name] = rawTemplate{file: path, fields: fields}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("load templates %s: %w", dir, err)
+	}
+	return raw, nil
+}
+
+// migrate 把模板升级到当前版本
+func migrate(raw map[string]rawTemplate, name string, migrations []Migration) error {
+	t := raw[name]
+	current, version := len(migrations)+1, 1
+	if v, ok := t.fields[versionKey]; ok {
+		n, ok := v.(int)
+		if !ok {
+			return fmt.Errorf("%s: template %q: %s must be an integer, got %T", t.file, name, versionKey, v)
+		}
+		version = n
+	}
+	if version < 1 || version > current {
+		return fmt.Errorf("%s: template %q: unsupported schema version %d, want 1 to %d", t.file, name, version, current)
+	}
+
+	own := make(map[string]any, len(t.fields))
+	for k, v := range t.fields {
+		if k != extendsKey && k != versionKey {
+			own[k] = v
+		}
+	}
+	for v := version; v < current; v++ {
+		if err := migrations[v-1](own); err != nil {
+			return fmt.Errorf("%s: template %q: migrate from version %d to %d: %w", t.file, name, v, v+1, err)
+		}
+	}
+	if parent, ok := t.fields[extendsKey]; ok {
+		own[extendsKey] = parent
+	}
+	t.fields = own
+	raw[name] = t
+	return nil
+}
+
+// templateResolver 沿 extends 合并模板，结果和错误都按模板名缓存
+type templateResolver struct {
+	raw      map[string]rawTemplate
+	resolved map[string]map[string]any
+	failed   map[string]error
+	visiting []string // 当前继承链，用于检测循环
+}
+
+func (r *templateResolver) resolve(name string) (map[string]any, error) {
+	if fields, ok := r.resolved[name]; ok {
+		return fields, nil
+	}
+	if err, ok := r.failed[name]; ok {
+		return nil, err
+	}
+	fields, err := r.merge(name)
+	if err != nil {
+		r.failed[name] = err
+		return nil, err
+	}
+	r.resolved[name] = fields
+	return fields, nil
+}
+
+func (r *templateResolver) merge(name string) (map[string]any, error) {
+	if i := slices.Index(r.visiting, name); i >= 0 {
+		return nil, fmt.Errorf("template cycle: %s", strings.Join(append(slices.Clone(r.visiting[i:]), name), " -> "))
+	}
+	t := r.raw[name]
+	own := make(map[string]any, len(t.fields))
+	for k, v := range t.fields {
+		if k != extendsKey {
+			own[k] = v
+		}
+	}
+
+	var base map[string]any
+	if v, ok := t.fields[extendsKey]; ok {
+		parent, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: template %q: %s must be a template name, got %T", t.file, name, extendsKey, v)
+		}
+		if _, ok := r.raw[parent]; !ok {
+			return nil, fmt.Errorf("%s: template %q extends unknown template %q", t.file, name, parent)
+		}
+		r.visiting = append(r.visiting, name)
+		var err error
+		base, err = r.resolve(parent)
+		r.visiting = r.visiting[:len(r.visiting)-1]
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fields, err := mergeFields(base, own, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s: template %q: %w", t.file, name, err)
+	}
+	return fields, nil
+}
+
+// mergeFields 用 over 覆盖 base，返回新的映射，不修改 base
+func mergeFields(base, over map[string]any, path string) (map[string]any, error) {
+	out := make(map[string]any, len(base)+len(over))
+	for k, v := range base {
+		out[k] = v
+	}
+	for _, k := range sortedKeys(over) {
+		v := over[k]
+		if field, ok := strings.CutSuffix(k, appendSuffix); ok {
+			if _, both := over[field]; both {
+				return nil, fmt.Errorf("%s%s: both %s and %s are set", path, field, field, k)
+			}
+			items, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s%s: want a list to append, got %T", path, k, v)
+			}
+			parent, ok := out[field].([]any)
+			if !ok && out[field] != nil {
+				return nil, fmt.Errorf("%s%s: cannot append to %T", path, k, out[field])
+			}
+			merged := slices.Clone(parent)
+			for _, item := range items {
+				if !slices.ContainsFunc(merged, func(x any) bool { return reflect.DeepEqual(x, item) }) {
+					merged = append(merged, item)
+				}
+			}
+			out[field] = merged
+			continue
+		}
+		sub, ok1 := v.(map[string]any)
+		parent, ok2 := out[k].(map[string]any)
+		if ok1 && ok2 {
+			merged, err := mergeFields(parent, sub, path+k+".")
+			if err != nil {
+				return nil, err
+			}
+			v = merged
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+var yamlLine = regexp.MustCompile(`^line \d+: `)
+
+// decodeTemplate 把合并后的字段解码为 T，T 中不存在的字段视为错误
+func decodeTemplate[T any](fields map[string]any) (T, error) {
+	var v T
+	data, err := yaml.Marshal(fields)
+	if err != nil {
+		return v, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&v); err != nil {
+		// 行号指向重新编码后的文档，对模板作者没有意义
+		var te *yaml.TypeError
+		if errors.As(err, &te) {
+			msgs := make([]string, len(te.Errors))
+			for i, msg := range te.Errors {
+				msgs[i] = yamlLine.ReplaceAllString(msg, "")
+			}
+			err = errors.New(strings.Join(msgs, "; "))
+		}
+		return v, err
+	}
+	return v, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
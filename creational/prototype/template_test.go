@@ -0,0 +1,205 @@
+package prototype
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplates(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	if dir == "" {
+		dir = t.TempDir()
+	}
+	for name, src := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644))
+	}
+	return dir
+}
+
+func TestLoadTemplates(t *testing.T) {
+	tmpl, err := LoadTemplates[*Character]("testdata/templates")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Character{Name: "战士", Level: 1, Skills: []string{"攻击", "防御"}}, tmpl["战士模板"])
+	assert.Equal(t, &Character{Name: "狂战士", Level: 1, Skills: []string{"攻击", "防御", "狂暴"}}, tmpl["狂战士"])
+	assert.Equal(t, &Character{Name: "战士", Level: 5, Skills: []string{"冲锋"}}, tmpl["骑士"])
+	assert.Equal(t, &Character{Name: "法师", Level: 20, Skills: []string{"火球", "冰箭", "传送"}}, tmpl["大法师"])
+	assert.Equal(t, &Character{Name: "战士", Level: 5, Skills: []string{"冲锋", "火球"}}, tmpl["魔剑士"])
+	assert.Len(t, tmpl, 6)
+}
+
+func TestLoadTemplatesNestedMerge(t *testing.T) {
+	type unit struct {
+		Name  string
+		Attrs map[string]int
+		Gear  map[string][]string
+	}
+	dir := writeTemplates(t, "", map[string]string{"units.yml": `
+base:
+  attrs: {hp: 100, mp: 10}
+  gear: {hand: [剑]}
+tank:
+  extends: base
+  attrs: {hp: 300}
+  gear: {hand+: [盾]}
+`})
+	tmpl, err := LoadTemplates[unit](dir)
+	require.NoError(t, err)
+	assert.Equal(t, unit{Attrs: map[string]int{"hp": 300, "mp": 10}, Gear: map[string][]string{"hand": {"剑", "盾"}}}, tmpl["tank"])
+	// 合并不会修改父模板
+	assert.Equal(t, unit{Attrs: map[string]int{"hp": 100, "mp": 10}, Gear: map[string][]string{"hand": {"剑"}}}, tmpl["base"])
+}
+
+func TestLoadTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{"cycle", map[string]string{"a.yaml": "a: {extends: b}\nb: {extends: c}\nc: {extends: a}\n"},
+			"template cycle: a -> b -> c -> a"},
+		{"self", map[string]string{"a.yaml": "a: {extends: a}\n"}, "template cycle: a -> a"},
+		{"unknown parent", map[string]string{"a.yaml": "a: {extends: 牧师}\n"}, `template "a" extends unknown template "牧师"`},
+		{"unknown field", map[string]string{"a.yaml": "a: {levl: 3}\n"},
+			`template "a": field levl not found in type prototype.Character`},
+		{"wrong type", map[string]string{"a.yaml": "a: {level: 高}\n"}, "cannot unmarshal !!str `高` into int"},
+		{"duplicate", map[string]string{"a.yaml": "a: {}\n", "b.yml": "a: {}\n"}, `b.yml: template "a" already defined in`},
+		{"not mapping", map[string]string{"a.yaml": "a: [1]\n"}, `template "a": want a mapping, got []interface {}`},
+		{"append and replace", map[string]string{"a.yaml": "a: {skills: [x], skills+: [y]}\n"},
+			"skills: both skills and skills+ are set"},
+		{"append scalar", map[string]string{"a.yaml": "a: {name: x}\nb: {extends: a, name+: [y]}\n"},
+			"name+: cannot append to string"},
+		{"extends not name", map[string]string{"a.yaml": "a: {extends: [b]}\n"}, "extends must be a template name"},
+		{"syntax", map[string]string{"a.yaml": "a: [\n"}, "a.yaml: yaml:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTemplates[*Character](writeTemplates(t, "", tt.files))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	// 循环上的每个模板都失败，但只报告一次
+	_, err := LoadTemplates[*Character](writeTemplates(t, "", map[string]string{"a.yaml": "a: {extends: b}\nb: {extends: a}\nc: {extends: b}\n"}))
+	require.Error(t, err)
+	assert.Equal(t, 1, strings.Count(err.Error(), "template cycle"), err.Error())
+
+	_, err = LoadTemplates[*Character]("testdata/missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestManagerLoadDir(t *testing.T) {
+	dir := writeTemplates(t, "", map[string]string{"roles.yaml": `
+战士模板: {name: 战士, skills: [攻击]}
+骑士: {extends: 战士模板, skills+: [冲锋]}
+`})
+	m := NewPrototypeManager[*Character]()
+	require.NoError(t, m.Register("手写", &Character{Name: "手写"}))
+	require.NoError(t, m.LoadDir(dir))
+	assert.Equal(t, []string{"战士模板", "手写", "骑士"}, m.Names())
+
+	knight, err := m.Clone("骑士")
+	require.NoError(t, err)
+
+	// 重新加载：修改骑士、删除战士模板
+	writeTemplates(t, dir, map[string]string{"roles.yaml": "骑士: {name: 圣骑士, skills: [治疗]}\n"})
+	require.NoError(t, m.LoadDir(dir))
+	assert.Equal(t, []string{"手写", "骑士"}, m.Names())
+	_, err = m.Clone("战士模板")
+	assert.ErrorIs(t, err, ErrPrototypeNotFound)
+
+	reloaded, err := m.Clone("骑士")
+	require.NoError(t, err)
+	assert.Equal(t, &Character{Name: "圣骑士", Skills: []string{"治疗"}}, reloaded)
+	// 重新加载前拿到的副本不变
+	assert.Equal(t, &Character{Name: "战士", Skills: []string{"攻击", "冲锋"}}, knight)
+
+	// 加载失败时注册表保持不变
+	writeTemplates(t, dir, map[string]string{"roles.yaml": "骑士: {extends: 骑士}\n"})
+	require.Error(t, m.LoadDir(dir))
+	assert.Equal(t, []string{"手写", "骑士"}, m.Names())
+}
+
+// characterMigrations Character 模板的历次结构调整
+var characterMigrations = []Migration{
+	// 版本 1 -> 2：单个技能 skill 改为列表 skills
+	func(fields map[string]any) error {
+		if v, ok := fields["skill"]; ok {
+			skill, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("skill: want a string, got %T", v)
+			}
+			fields["skills"] = []any{skill}
+			delete(fields, "skill")
+		}
+		return nil
+	},
+	// 版本 2 -> 3：lv 改名为 level
+	func(fields map[string]any) error {
+		if v, ok := fields["lv"]; ok {
+			fields["level"] = v
+			delete(fields, "lv")
+		}
+		return nil
+	},
+}
+
+func TestLoadTemplatesMigrations(t *testing.T) {
+	tmpl, err := LoadTemplates[*Character]("testdata/legacy", characterMigrations...)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*Character{
+		"老战士": {Name: "战士", Level: 3, Skills: []string{"攻击"}},
+		"弓手":  {Name: "弓手", Level: 2, Skills: []string{"射击"}},
+		"老弓手": {Name: "战士", Level: 3, Skills: []string{"攻击", "射击"}},
+		"游侠":  {Name: "弓手", Level: 10, Skills: []string{"射击"}},
+	}, tmpl)
+
+	// 只注册了一个升级函数时当前版本是 2，版本 3 的模板不被支持
+	_, err = LoadTemplates[*Character]("testdata/legacy", characterMigrations[0])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `v3.yaml: template "游侠": unsupported schema version 3, want 1 to 2`)
+}
+
+func TestLoadTemplatesMigrationErrors(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"too new", "a: {version: 4}\n", `template "a": unsupported schema version 4, want 1 to 3`},
+		{"zero", "a: {version: 0}\n", "unsupported schema version 0, want 1 to 3"},
+		{"not integer", "a: {version: 二}\n", "version must be an integer, got string"},
+		{"migration failed", "a: {skill: [x]}\n", `template "a": migrate from version 1 to 2: skill: want a string, got []interface {}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTemplates[*Character](writeTemplates(t, "", map[string]string{"a.yaml": tt.src}), characterMigrations...)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestManagerMigrations(t *testing.T) {
+	m := NewPrototypeManager[*Character]()
+	assert.Equal(t, 1, m.SchemaVersion())
+	assert.EqualError(t, m.AddMigration(2, characterMigrations[1]),
+		"prototype: migration must start from the current schema version 1, got 2")
+
+	// 注册升级函数之前当前版本是 1，版本 2、3 的模板不被支持
+	require.Error(t, m.LoadDir("testdata/legacy"))
+
+	for i, fn := range characterMigrations {
+		require.NoError(t, m.AddMigration(i+1, fn))
+	}
+	assert.Equal(t, 3, m.SchemaVersion())
+	require.NoError(t, m.LoadDir("testdata/legacy"))
+	c, err := m.Clone("老弓手")
+	require.NoError(t, err)
+	assert.Equal(t, &Character{Name: "战士", Level: 3, Skills: []string{"攻击", "射击"}}, c)
+}